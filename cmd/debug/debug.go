@@ -0,0 +1,246 @@
+package debug
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/execsession"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+const defaultShellCommand = "/bin/sh"
+
+type debugOptions struct {
+	ClusterInput   string
+	ServiceInput   string
+	ContainerInput string
+	Image          string
+	Command        string
+	TTL            time.Duration
+	Timeout        time.Duration
+	Interval       time.Duration
+	// AttachTask is set when going runs itself to open the session, see attach.
+	AttachTask string
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &debugOptions{}
+
+func NewCmdDebug(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Open a shell in a temporary task next to a service",
+		Long: `Open a shell in a temporary task next to a service.
+
+A task is started in the same subnets and security groups as the service with
+ExecuteCommand enabled. By default it runs a temporary copy of the service's
+task definition with the container's entrypoint and command replaced by sleep,
+so it has the same image, roles, and configuration as the service without
+serving traffic. The image must have a sleep binary, distroless and scratch
+images don't, so use --image for those. With --image, or the debug_image
+setting in the going config, a toolbox image runs in a temporary task
+definition with the service's roles instead.
+
+The task is stopped when the shell exits or ctrl+c is pressed while waiting for
+it. In case that fails it stops by itself once the --ttl has passed.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+			if opts.ContainerInput == "" {
+				opts.ContainerInput = s.Container
+			}
+			if opts.Image == "" {
+				opts.Image = s.DebugImage
+			}
+			if opts.Command == "" {
+				opts.Command = s.Shell
+			}
+			if opts.Command == "" {
+				opts.Command = defaultShellCommand
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			if opts.AttachTask != "" {
+				attach(f)
+				return
+			}
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			service, err := opts.client.DescribeService(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			definition, err := opts.client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+			utils.CheckErr(err)
+
+			container, input, cleanup := runTaskInput(service, definition)
+
+			taskARN, err := opts.client.RunTask(input)
+			if err != nil {
+				cleanup()
+				utils.CheckErr(err)
+			}
+			taskID, _ := utils.Last(strings.Split(taskARN, "/"))
+			fmt.Printf("Started debug task %s, it stops by itself after %s\n", taskID, opts.TTL)
+
+			stop := func() {
+				fmt.Printf("Stopping debug task %s\n", taskID)
+				err := opts.client.StopTask(opts.ClusterInput, taskARN, "going debug session ended")
+				if err != nil {
+					_, _ = fmt.Fprintln(os.Stderr, "Warning: failed to stop the debug task:", err)
+				}
+				cleanup()
+			}
+
+			// The task is stopped if waiting for it is interrupted, the
+			// session handles ctrl+c itself once it's open.
+			interrupts := make(chan os.Signal, 1)
+			signal.Notify(interrupts, os.Interrupt)
+			go func() {
+				if _, ok := <-interrupts; ok {
+					fmt.Println()
+					stop()
+					os.Exit(130)
+				}
+			}()
+
+			waiter := &deploy.TaskWaiter{
+				Client:   opts.client,
+				Cluster:  opts.ClusterInput,
+				Interval: opts.Interval,
+				Timeout:  opts.Timeout,
+				Out:      os.Stdout,
+			}
+			_, err = waiter.Wait(taskARN, deploy.IsExecReady(container))
+			signal.Stop(interrupts)
+			close(interrupts)
+			if err != nil {
+				stop()
+				utils.CheckErr(err)
+			}
+
+			err = openSession(f, taskARN, container)
+			stop()
+			utils.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVarP(&opts.ContainerInput, "container", "r", "",
+		"The container of the service's task definition to debug, not used with --image")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "A toolbox image to run instead of the service's task definition")
+	cmd.Flags().StringVar(&opts.Command, "command", "", "The shell command, defaults to /bin/sh")
+	cmd.Flags().DurationVar(&opts.TTL, "ttl", time.Hour, "How long the task runs if it isn't stopped")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "How long to wait for the task to be ready")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 5*time.Second, "How often to poll the task")
+	cmd.Flags().StringVar(&opts.AttachTask, "attach", "", "Open the session to the debug task with this ARN")
+	_ = cmd.Flags().MarkHidden("attach")
+
+	return cmd
+}
+
+// runTaskInput returns the container to connect to, the input to start the
+// debug task, and a function that removes the debug task definition.
+func runTaskInput(service types.Service, definition *types.TaskDefinition) (string, *ecs.RunTaskInput, func()) {
+	var container string
+	var register *ecs.RegisterTaskDefinitionInput
+	if opts.Image == "" {
+		c, err := opts.selector.ContainerDefinition(definition, opts.ContainerInput)
+		utils.CheckErr(err)
+		container = aws.ToString(c.Name)
+		register = deploy.SleepTaskDefinitionInput(definition, container, opts.TTL)
+	} else {
+		container = deploy.DebugContainer
+		register = deploy.DebugTaskDefinitionInput(definition, opts.Image, opts.TTL)
+	}
+
+	debugDefinition, err := opts.client.RegisterTaskDefinition(register)
+	utils.CheckErr(err)
+
+	service.TaskDefinition = debugDefinition.TaskDefinitionArn
+	input := deploy.RunTaskInput(opts.ClusterInput, service, deploy.TaskOverrides{EnableExecuteCommand: true})
+	return container, input, func() {
+		err := opts.client.DeregisterTaskDefinition(aws.ToString(debugDefinition.TaskDefinitionArn))
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Warning: failed to deregister the debug task definition:", err)
+		}
+	}
+}
+
+// openSession runs going again to open the session. The session manager
+// plugin exits the process when the session ends, running it in a child
+// process lets this one stop the task afterwards.
+func openSession(f *factory.Factory, taskARN string, container string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"debug",
+		"--attach", taskARN,
+		"--cluster", opts.ClusterInput,
+		"--container", container,
+		"--command", opts.Command,
+		"--region", f.Config().Region,
+	}
+	// Without a profile the child uses the same default credentials.
+	if f.ProfileName != "" {
+		args = append(args, "--profile", f.ProfileName)
+	}
+	child := exec.Command(executable, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// The session handles ctrl+c, this process only has to outlive it.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	return child.Run()
+}
+
+// attach opens the session to the container of the debug task.
+func attach(f *factory.Factory) {
+	task, err := opts.client.DescribeTask(opts.ClusterInput, opts.AttachTask)
+	utils.CheckErr(err)
+
+	for _, c := range task.Containers {
+		if c.Name == opts.ContainerInput {
+			utils.CheckErr(execsession.Exec(f, opts.client, c, opts.Command, nil))
+			return
+		}
+	}
+	utils.CheckErr(fmt.Errorf("no container '%s' in debug task %s", opts.ContainerInput, task.ID()))
+}
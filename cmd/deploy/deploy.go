@@ -0,0 +1,18 @@
+package deploy
+
+import (
+	"github.com/spf13/cobra"
+
+	"going/internal/factory"
+)
+
+func NewCmdDeploy(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Work with ECS service deployments",
+	}
+
+	cmd.AddCommand(NewCmdWatch(f))
+
+	return cmd
+}
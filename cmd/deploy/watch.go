@@ -0,0 +1,83 @@
+package deploy
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type watchOptions struct {
+	ClusterInput string
+	ServiceInput string
+	Timeout      time.Duration
+	Interval     time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var watchOpts = &watchOptions{}
+
+func NewCmdWatch(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch a service's deployment until it completes",
+		Long: `Watch a service's deployment until it completes.
+
+New service events, deployment state changes, and tasks starting and stopping
+are printed as they happen. The command exits successfully when the primary
+deployment's rollout state is COMPLETED, and with an error when it is FAILED or
+the timeout is reached.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			watchOpts.client = client.New(f.Context, f.Config())
+			watchOpts.selector = selector.New(f, watchOpts.client)
+			s := f.Settings()
+			if watchOpts.ClusterInput == "" {
+				watchOpts.ClusterInput = s.Cluster
+			}
+			if watchOpts.ServiceInput == "" {
+				watchOpts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			watchOpts.ClusterInput, err = watchOpts.selector.Cluster(watchOpts.ClusterInput)
+			utils.CheckErr(err)
+
+			watchOpts.ServiceInput, err = watchOpts.selector.Service(watchOpts.ClusterInput, watchOpts.ServiceInput)
+			utils.CheckErr(err)
+
+			w := &deploy.Watcher{
+				Client:   watchOpts.client,
+				Cluster:  watchOpts.ClusterInput,
+				Service:  watchOpts.ServiceInput,
+				Interval: watchOpts.Interval,
+				Timeout:  watchOpts.Timeout,
+				Out:      os.Stdout,
+			}
+			utils.CheckErr(w.Watch())
+		},
+	}
+
+	cmd.Flags().StringVarP(&watchOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&watchOpts.ServiceInput, "service", "s", "", "The service name")
+	AddWatchFlags(cmd, &watchOpts.Timeout, &watchOpts.Interval)
+
+	return cmd
+}
+
+// AddWatchFlags adds the flags that control how long and how often a deployment is watched.
+func AddWatchFlags(cmd *cobra.Command, timeout *time.Duration, interval *time.Duration) {
+	cmd.Flags().DurationVar(timeout, "timeout", 30*time.Minute, "How long to wait for the deployment to complete")
+	cmd.Flags().DurationVar(interval, "interval", 5*time.Second, "How often to poll the service")
+}
@@ -0,0 +1,18 @@
+package doctor
+
+import (
+	"github.com/spf13/cobra"
+
+	"going/internal/factory"
+)
+
+func NewCmdDoctor(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems connecting to ECS",
+	}
+
+	cmd.AddCommand(NewCmdExec(f))
+
+	return cmd
+}
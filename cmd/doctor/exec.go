@@ -0,0 +1,127 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/execcheck"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type execOptions struct {
+	ClusterInput string
+	ServiceInput string
+	TaskInput    string
+	TaskPolicy   string
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var execOpts = &execOptions{}
+
+func NewCmdExec(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Check if a task is ready for ExecuteCommand sessions",
+		Long: `Check if a task is ready for ExecuteCommand sessions.
+
+Like amazon-ecs-exec-checker the task's ExecuteCommand flag and agent status,
+the task role's ssmmessages permissions, the ssmmessages VPC endpoint, the
+cluster's KMS encryption key, the session manager plugin, and your own
+ecs:ExecuteCommand permission are checked and printed as a checklist. The
+command fails when any check fails.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			execOpts.client = client.New(f.Context, f.Config())
+			execOpts.selector = selector.New(f, execOpts.client)
+			s := f.Settings()
+			if execOpts.ClusterInput == "" {
+				execOpts.ClusterInput = s.Cluster
+			}
+			if execOpts.ServiceInput == "" {
+				execOpts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			execOpts.ClusterInput, err = execOpts.selector.Cluster(execOpts.ClusterInput)
+			utils.CheckErr(err)
+
+			execOpts.ServiceInput, err = execOpts.selector.Service(execOpts.ClusterInput, execOpts.ServiceInput)
+			utils.CheckErr(err)
+
+			taskARN, err := execOpts.selector.Task(execOpts.ClusterInput, execOpts.ServiceInput, execOpts.TaskInput,
+				execOpts.TaskPolicy)
+			utils.CheckErr(err)
+
+			task, err := execOpts.client.DescribeTask(execOpts.ClusterInput, taskARN)
+			utils.CheckErr(err)
+
+			definition, err := execOpts.client.DescribeTaskDefinition(task.DefinitionARN)
+			utils.CheckErr(err)
+
+			cluster, err := execOpts.client.DescribeCluster(execOpts.ClusterInput)
+			utils.CheckErr(err)
+
+			fmt.Printf("Checking task %s of service %s\n\n", task.ID(), execOpts.ServiceInput)
+
+			results := []execcheck.Result{
+				execcheck.TaskEnabled(task),
+				execcheck.TaskAgents([]client.Task{task}),
+				execcheck.TaskRole(definition, execOpts.client),
+				ssmMessagesEndpoint(f, task),
+				execcheck.ClusterKMS(cluster, definition, execOpts.client),
+				execcheck.PluginVersion(),
+				callerExecuteCommand(task),
+			}
+			execcheck.Print(os.Stdout, results)
+
+			if execcheck.Failed(results) {
+				utils.CheckErr(fmt.Errorf("ExecuteCommand isn't ready for task %s", task.ID()))
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&execOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&execOpts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVar(&execOpts.TaskInput, "task", "", "The task ID or ARN")
+	cmd.Flags().StringVar(&execOpts.TaskPolicy, "task-policy", "",
+		"How to pick a task when multiple are running: newest, random, or healthy")
+
+	return cmd
+}
+
+func ssmMessagesEndpoint(f *factory.Factory, task client.Task) execcheck.Result {
+	if task.SubnetID == "" {
+		return execcheck.SSMMessagesEndpoint("", nil, nil)
+	}
+
+	vpcID, err := execOpts.client.SubnetVPC(task.SubnetID)
+	if err != nil {
+		return execcheck.SSMMessagesEndpoint("", nil, err)
+	}
+
+	serviceName := fmt.Sprintf("com.amazonaws.%s.ssmmessages", f.Config().Region)
+	endpoints, err := execOpts.client.VPCEndpoints(vpcID, serviceName)
+	return execcheck.SSMMessagesEndpoint(vpcID, endpoints, err)
+}
+
+func callerExecuteCommand(task client.Task) execcheck.Result {
+	callerARN, err := execOpts.client.CallerARN()
+	if err != nil {
+		return execcheck.Result{
+			Name:   "You are allowed ecs:ExecuteCommand",
+			Status: execcheck.StatusWarn,
+			Detail: fmt.Sprintf("Couldn't get your IAM identity: %s", err),
+		}
+	}
+	return execcheck.CallerExecuteCommand(callerARN, task, execOpts.client)
+}
@@ -0,0 +1,35 @@
+package ecs
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/utils"
+)
+
+var clusterColumns = []output.Column[client.Cluster]{
+	{Header: "NAME", Value: func(c client.Cluster) string { return c.Name }},
+	{Header: "ARN", Wide: true, Value: func(c client.Cluster) string { return c.ARN }},
+}
+
+func NewCmdClusters(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "List the ECS clusters",
+		Run: func(cmd *cobra.Command, args []string) {
+			setup(f)
+
+			clusters, err := opts.client.ListClusters()
+			utils.CheckErr(err)
+
+			err = output.Print(os.Stdout, opts.Output, clusters, clusterColumns)
+			utils.CheckErr(err)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/utils"
+)
+
+var containerColumns = []output.Column[client.Container]{
+	{Header: "TASK", Value: func(c client.Container) string { return c.TaskID() }},
+	{Header: "NAME", Value: func(c client.Container) string { return c.Name }},
+	{Header: "STATUS", Value: func(c client.Container) string { return c.LastStatus }},
+	{Header: "HEALTH", Value: func(c client.Container) string { return c.Health }},
+	{Header: "IMAGE", Value: func(c client.Container) string { return c.Image }},
+	{Header: "EXEC AGENT", Wide: true, Value: func(c client.Container) string {
+		return strconv.FormatBool(c.ExecuteAgentRunning)
+	}},
+	{Header: "CPU", Wide: true, Value: func(c client.Container) string { return c.CPU }},
+	{Header: "MEMORY", Wide: true, Value: func(c client.Container) string { return c.Memory }},
+	{Header: "RUNTIME ID", Wide: true, Value: func(c client.Container) string { return c.RuntimeID }},
+	{Header: "DIGEST", Wide: true, Value: func(c client.Container) string { return c.ImageDigest }},
+}
+
+func NewCmdContainers(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "containers",
+		Short: "List the containers of a service's running tasks",
+		Run: func(cmd *cobra.Command, args []string) {
+			setup(f)
+			selectService()
+
+			tasks, err := describeServiceTasks()
+			utils.CheckErr(err)
+
+			var containers []client.Container
+			for _, t := range tasks {
+				if opts.TaskInput != "" && t.ARN != opts.TaskInput && t.ID() != opts.TaskInput {
+					continue
+				}
+				containers = append(containers, t.Containers...)
+			}
+
+			err = output.Print(os.Stdout, opts.Output, containers, containerColumns)
+			utils.CheckErr(err)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.TaskInput, "task", "", "Only list the containers of the task with this ID or ARN")
+
+	return cmd
+}
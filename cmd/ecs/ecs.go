@@ -0,0 +1,84 @@
+package ecs
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type ecsOptions struct {
+	ClusterInput string
+	ServiceInput string
+	TaskInput    string
+	Output       output.Options
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &ecsOptions{}
+
+func NewCmdECS(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ecs",
+		Short: "List ECS resources",
+		Long: `List ECS clusters, services, tasks, and containers.
+
+The output can be a table, JSON, or YAML so it can be piped to other tools. The
+--template flag takes a Go template that is executed for each item.`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.PersistentFlags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	output.AddFlags(cmd, &opts.Output)
+
+	cmd.AddCommand(NewCmdClusters(f))
+	cmd.AddCommand(NewCmdServices(f))
+	cmd.AddCommand(NewCmdTasks(f))
+	cmd.AddCommand(NewCmdContainers(f))
+
+	return cmd
+}
+
+// setup creates the client and makes sure we are logged in.
+func setup(f *factory.Factory) {
+	opts.client = client.New(f.Context, f.Config())
+	opts.selector = selector.New(f, opts.client)
+
+	s := f.Settings()
+	if opts.ClusterInput == "" {
+		opts.ClusterInput = s.Cluster
+	}
+	if opts.ServiceInput == "" {
+		opts.ServiceInput = s.Service
+	}
+
+	err := internal.CheckSSOLogin(f)
+	utils.CheckErr(err)
+}
+
+// selectService makes sure the cluster and service are selected.
+func selectService() {
+	var err error
+	opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+	utils.CheckErr(err)
+
+	opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+	utils.CheckErr(err)
+}
+
+// shortARN returns the part of the ARN after the resource type.
+func shortARN(arn string) string {
+	_, name, found := strings.Cut(arn, "/")
+	if !found {
+		return arn
+	}
+	return name
+}
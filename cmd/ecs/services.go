@@ -0,0 +1,39 @@
+package ecs
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/utils"
+)
+
+var serviceColumns = []output.Column[client.Service]{
+	{Header: "NAME", Value: func(s client.Service) string { return s.Name }},
+	{Header: "ARN", Wide: true, Value: func(s client.Service) string { return s.ARN }},
+}
+
+func NewCmdServices(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "services",
+		Short: "List the services in a cluster",
+		Run: func(cmd *cobra.Command, args []string) {
+			setup(f)
+
+			var err error
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			services, err := opts.client.ListServices(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			err = output.Print(os.Stdout, opts.Output, services, serviceColumns)
+			utils.CheckErr(err)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,61 @@
+package ecs
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/utils"
+)
+
+var taskColumns = []output.Column[client.Task]{
+	{Header: "ID", Value: func(t client.Task) string { return t.ID() }},
+	{Header: "STATUS", Value: func(t client.Task) string { return t.LastStatus }},
+	{Header: "HEALTH", Value: func(t client.Task) string { return t.Health }},
+	{Header: "DEFINITION", Value: func(t client.Task) string { return shortARN(t.DefinitionARN) }},
+	{Header: "STARTED", Value: func(t client.Task) string { return formatTime(t.StartedAt) }},
+	{Header: "CONTAINERS", Wide: true, Value: func(t client.Task) string { return strconv.Itoa(len(t.Containers)) }},
+	{Header: "DESIRED", Wide: true, Value: func(t client.Task) string { return t.DesiredStatus }},
+	{Header: "ARN", Wide: true, Value: func(t client.Task) string { return t.ARN }},
+}
+
+func NewCmdTasks(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "List the running tasks of a service",
+		Run: func(cmd *cobra.Command, args []string) {
+			setup(f)
+			selectService()
+
+			tasks, err := describeServiceTasks()
+			utils.CheckErr(err)
+
+			err = output.Print(os.Stdout, opts.Output, tasks, taskColumns)
+			utils.CheckErr(err)
+		},
+	}
+
+	return cmd
+}
+
+// describeServiceTasks returns the running tasks of the selected service.
+func describeServiceTasks() ([]client.Task, error) {
+	arns, err := opts.client.ListTasks(opts.ClusterInput, opts.ServiceInput)
+	if err != nil {
+		return nil, err
+	}
+
+	return opts.client.DescribeTasks(opts.ClusterInput, arns...)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,137 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/output"
+	"going/internal/selector"
+	"going/internal/taskdef"
+	"going/internal/utils"
+)
+
+type envOptions struct {
+	ClusterInput        string
+	ServiceInput        string
+	ContainerInput      string
+	TaskDefinitionInput string
+	Reveal              bool
+	Dotenv              bool
+	Output              output.Options
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &envOptions{}
+
+var columns = []output.Column[taskdef.Variable]{
+	{Header: "NAME", Value: func(v taskdef.Variable) string { return v.Name }},
+	{Header: "VALUE", Value: func(v taskdef.Variable) string {
+		// Secrets that aren't revealed show where they are read from.
+		if v.Secret && !opts.Reveal {
+			return v.From
+		}
+		return v.Value
+	}},
+	{Header: "SOURCE", Value: func(v taskdef.Variable) string { return v.Source }},
+	{Header: "FROM", Wide: true, Value: func(v taskdef.Variable) string { return v.From }},
+}
+
+func NewCmdEnv(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env [alias]",
+		Short: "Show the effective environment of a container",
+		Long: `Show the effective environment of a container.
+
+The container's environment variables, the variables in its environment files
+in S3, and its secrets from SSM Parameter Store and Secrets Manager are merged
+the way ECS does when starting the container. The task definition the service
+is running is used unless --task-definition is given.
+
+Secrets are only read with --reveal, which needs access to the parameters and
+secrets, otherwise the parameter or secret they are read from is shown. Use
+--dotenv to write the environment as a .env file to reproduce the service
+locally, secrets that aren't revealed are written as comments.`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{factory.AliasAnnotation: "true"},
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+			if opts.ContainerInput == "" {
+				opts.ContainerInput = s.Container
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			definitionARN := opts.TaskDefinitionInput
+			if definitionARN == "" {
+				opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+				utils.CheckErr(err)
+
+				opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+				utils.CheckErr(err)
+
+				service, err := opts.client.DescribeService(opts.ClusterInput, opts.ServiceInput)
+				utils.CheckErr(err)
+				definitionARN = aws.ToString(service.TaskDefinition)
+			}
+
+			definition, err := opts.client.DescribeTaskDefinition(definitionARN)
+			utils.CheckErr(err)
+
+			container, err := opts.selector.ContainerDefinition(definition, opts.ContainerInput)
+			utils.CheckErr(err)
+
+			variables, err := taskdef.Environment(container, opts.client, opts.Reveal)
+			utils.CheckErr(err)
+
+			var secrets []string
+			if !opts.Reveal {
+				for _, v := range variables {
+					if v.Secret {
+						secrets = append(secrets, v.Name)
+					}
+				}
+			}
+
+			if opts.Dotenv {
+				fmt.Print(taskdef.FormatDotenv(variables, opts.Reveal))
+			} else {
+				err = output.Print(os.Stdout, opts.Output, variables, columns)
+				utils.CheckErr(err)
+			}
+
+			if len(secrets) > 0 {
+				_, _ = fmt.Fprintf(os.Stderr, "The secrets %s aren't read, use --reveal to show them.\n", strings.Join(secrets, ", "))
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVarP(&opts.ContainerInput, "container", "r", "", "The container name")
+	cmd.Flags().StringVar(&opts.TaskDefinitionInput, "task-definition", "",
+		"The task definition family:revision or ARN to use instead of the service's")
+	cmd.Flags().BoolVar(&opts.Reveal, "reveal", false, "Read and show the values of secrets")
+	cmd.Flags().BoolVar(&opts.Dotenv, "dotenv", false, "Output the environment as a .env file")
+	output.AddFlags(cmd, &opts.Output)
+
+	return cmd
+}
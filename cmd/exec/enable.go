@@ -0,0 +1,134 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+
+	deploycmd "going/cmd/deploy"
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/execcheck"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type enableOptions struct {
+	ClusterInput string
+	ServiceInput string
+	Watch        bool
+	Timeout      time.Duration
+	Interval     time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var enableOpts = &enableOptions{}
+
+func NewCmdEnable(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Enable ExecuteCommand on a service",
+		Long: `Enable ExecuteCommand on a service.
+
+The service's EnableExecuteCommand flag, the Fargate platform version, the task
+role's ssmmessages permissions, and the agent in the running tasks are checked.
+When the prerequisites pass the service is updated with EnableExecuteCommand
+and a new deployment is forced so the tasks start with the agent.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			enableOpts.client = client.New(f.Context, f.Config())
+			enableOpts.selector = selector.New(f, enableOpts.client)
+			s := f.Settings()
+			if enableOpts.ClusterInput == "" {
+				enableOpts.ClusterInput = s.Cluster
+			}
+			if enableOpts.ServiceInput == "" {
+				enableOpts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			enableOpts.ClusterInput, err = enableOpts.selector.Cluster(enableOpts.ClusterInput)
+			utils.CheckErr(err)
+
+			enableOpts.ServiceInput, err = enableOpts.selector.Service(enableOpts.ClusterInput, enableOpts.ServiceInput)
+			utils.CheckErr(err)
+
+			service, err := enableOpts.client.DescribeService(enableOpts.ClusterInput, enableOpts.ServiceInput)
+			utils.CheckErr(err)
+
+			definition, err := enableOpts.client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+			utils.CheckErr(err)
+
+			taskARNs, err := enableOpts.client.ListTasks(enableOpts.ClusterInput, enableOpts.ServiceInput)
+			utils.CheckErr(err)
+			tasks, err := enableOpts.client.DescribeTasks(enableOpts.ClusterInput, taskARNs...)
+			utils.CheckErr(err)
+
+			prerequisites := []execcheck.Result{
+				execcheck.PlatformVersion(service),
+				execcheck.TaskRole(definition, enableOpts.client),
+			}
+			enabled := execcheck.ServiceEnabled(service)
+			agents := execcheck.TaskAgents(tasks)
+			execcheck.Print(os.Stdout, append([]execcheck.Result{enabled}, append(prerequisites, agents)...))
+
+			if execcheck.Failed(prerequisites) {
+				utils.CheckErr(fmt.Errorf("fix the failed checks before enabling ExecuteCommand"))
+			}
+
+			label := fmt.Sprintf("Enable ExecuteCommand on service %s and force a new deployment", enableOpts.ServiceInput)
+			if service.EnableExecuteCommand {
+				if agents.Status != execcheck.StatusFail {
+					fmt.Println("ExecuteCommand is already enabled.")
+					return
+				}
+				label = fmt.Sprintf("Force a new deployment of service %s so the tasks start with the agent",
+					enableOpts.ServiceInput)
+			}
+
+			yes, err := f.Prompt.YesNoPrompt(label)
+			utils.CheckErr(err)
+			if !yes {
+				os.Exit(0)
+			}
+
+			err = enableOpts.client.UpdateService(&ecs.UpdateServiceInput{
+				Cluster:              aws.String(enableOpts.ClusterInput),
+				Service:              aws.String(enableOpts.ServiceInput),
+				EnableExecuteCommand: aws.Bool(true),
+				ForceNewDeployment:   true,
+			})
+			utils.CheckErr(err)
+			fmt.Printf("Enabled ExecuteCommand and started a new deployment of service %s.\n", enableOpts.ServiceInput)
+
+			if enableOpts.Watch {
+				w := &deploy.Watcher{
+					Client:   enableOpts.client,
+					Cluster:  enableOpts.ClusterInput,
+					Service:  enableOpts.ServiceInput,
+					Interval: enableOpts.Interval,
+					Timeout:  enableOpts.Timeout,
+					Out:      os.Stdout,
+				}
+				utils.CheckErr(w.Watch())
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&enableOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&enableOpts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().BoolVarP(&enableOpts.Watch, "watch", "w", false, "Watch the deployment until it completes")
+	deploycmd.AddWatchFlags(cmd, &enableOpts.Timeout, &enableOpts.Interval)
+
+	return cmd
+}
@@ -0,0 +1,18 @@
+package exec
+
+import (
+	"github.com/spf13/cobra"
+
+	"going/internal/factory"
+)
+
+func NewCmdExec(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Set up ECS ExecuteCommand for a service",
+	}
+
+	cmd.AddCommand(NewCmdEnable(f))
+
+	return cmd
+}
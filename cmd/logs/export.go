@@ -0,0 +1,192 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/logs"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type exportOptions struct {
+	ClusterInput   string
+	ServiceInput   string
+	ContainerInput string
+	TaskInput      string
+	Group          string
+	StreamPrefix   string
+	Since          string
+	Until          string
+	Filter         string
+	File           string
+	Format         string
+	Gzip           bool
+	Restart        bool
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var exportOpts = &exportOptions{}
+
+func NewCmdExport(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the CloudWatch logs of a container in a time range to a file",
+		Long: `Export the CloudWatch logs of a container in a time range to a file.
+
+The log group and stream prefix are read from the awslogs or FireLens
+configuration of the container in the service's task definition, exporting
+the container's logs from every task. Use --task for a single task's stream,
+or --group and --stream-prefix to export other streams without looking up the
+service.
+
+Events are written as NDJSON or text, compressed with gzip when the file ends
+in .gz or --gzip is given. The progress is saved next to the file after each
+page, running the same command again resumes an interrupted export.`,
+		Example: `  going logs export -s api --since "yesterday 14:00" --until "yesterday 15:00" -f incident.ndjson
+  going logs export --group /ecs/api --stream-prefix ecs/web/ --since 24h -f api.log.gz --format text`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			exportOpts.client = client.New(f.Context, f.Config())
+			exportOpts.selector = selector.New(f, exportOpts.client)
+			s := f.Settings()
+			if exportOpts.ClusterInput == "" {
+				exportOpts.ClusterInput = s.Cluster
+			}
+			if exportOpts.ServiceInput == "" {
+				exportOpts.ServiceInput = s.Service
+			}
+			if exportOpts.ContainerInput == "" {
+				exportOpts.ContainerInput = s.Container
+			}
+			if !cmd.Flags().Changed("gzip") && strings.HasSuffix(exportOpts.File, ".gz") {
+				exportOpts.Gzip = true
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			now := time.Now()
+			startTime, err := logs.ParseTime(exportOpts.Since, now)
+			utils.CheckErr(err)
+			endTime := now
+			if exportOpts.Until != "" {
+				endTime, err = logs.ParseTime(exportOpts.Until, now)
+				utils.CheckErr(err)
+			}
+			if !endTime.After(startTime) {
+				utils.CheckErr(fmt.Errorf("--until %s isn't after --since %s",
+					endTime.Format(time.RFC3339), startTime.Format(time.RFC3339)))
+			}
+
+			err = internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			query := client.LogQuery{
+				GroupName:     exportOpts.Group,
+				StreamPrefix:  exportOpts.StreamPrefix,
+				StartTime:     startTime,
+				EndTime:       endTime,
+				FilterPattern: exportOpts.Filter,
+			}
+			if query.GroupName == "" {
+				query = exportQuery(query)
+			}
+
+			stream := query.StreamPrefix + "*"
+			if len(query.StreamNames) > 0 {
+				stream = query.StreamNames[0]
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Exporting group \"%s\" stream \"%s\" from %s to %s into %s\n",
+				query.GroupName, stream, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339), exportOpts.File)
+
+			// The progress of the last page is saved, an interrupted page is exported again on resume.
+			sigChan := make(chan os.Signal, 1)
+			signal.Notify(sigChan, os.Interrupt)
+			go func() {
+				<-sigChan
+				_, _ = fmt.Fprintln(os.Stderr, "\nInterrupted, run the same command again to resume the export.")
+				os.Exit(130)
+			}()
+
+			export := &logs.Export{
+				Client:   exportOpts.client,
+				Query:    query,
+				Window:   exportWindow(),
+				Filename: exportOpts.File,
+				Format:   exportOpts.Format,
+				Gzip:     exportOpts.Gzip,
+				Restart:  exportOpts.Restart,
+				Progress: os.Stderr,
+			}
+			n, err := export.Run()
+			if err != nil {
+				utils.CheckErr(fmt.Errorf("export stopped after %d events, run the same command again to resume, %w", n, err))
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "Exported %d events to %s\n", n, exportOpts.File)
+		},
+	}
+
+	cmd.Flags().StringVarP(&exportOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&exportOpts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVarP(&exportOpts.ContainerInput, "container", "r", "", "The container name")
+	cmd.Flags().StringVar(&exportOpts.TaskInput, "task", "", "Only export the stream of this task ID")
+	cmd.Flags().StringVar(&exportOpts.Group, "group", "", "Export this log group instead of the container's")
+	cmd.Flags().StringVar(&exportOpts.StreamPrefix, "stream-prefix", "", "Only export the streams starting with this prefix of --group")
+	cmd.Flags().StringVar(&exportOpts.Since, "since", "1h",
+		"Export from a time: RFC3339, a duration back from now like 6h, or a day like 'yesterday 14:00'")
+	cmd.Flags().StringVar(&exportOpts.Until, "until", "", "Export up to a time in the same formats as --since (default now)")
+	cmd.Flags().StringVar(&exportOpts.Filter, "filter", "", "Only export events matching a CloudWatch Logs filter pattern")
+	cmd.Flags().StringVarP(&exportOpts.File, "file", "f", "", "The file to write")
+	cmd.Flags().StringVar(&exportOpts.Format, "format", logs.ExportNDJSON,
+		"The format of the events: "+strings.Join(logs.ExportFormats, ", "))
+	cmd.Flags().BoolVar(&exportOpts.Gzip, "gzip", false, "Compress the file with gzip, the default when it ends in .gz")
+	cmd.Flags().BoolVar(&exportOpts.Restart, "restart", false, "Start over instead of resuming an interrupted export")
+	_ = cmd.MarkFlagRequired("file")
+	cmd.MarkFlagsMutuallyExclusive("group", "service")
+	cmd.MarkFlagsMutuallyExclusive("group", "task")
+
+	return cmd
+}
+
+// exportWindow returns the time range as given, the same for each run of a
+// command with relative times so it can be resumed.
+func exportWindow() string {
+	until := exportOpts.Until
+	if until == "" {
+		until = "now"
+	}
+	return exportOpts.Since + " to " + until
+}
+
+// exportQuery sets the group and streams of the query from the container's
+// log configuration in the service's task definition.
+func exportQuery(query client.LogQuery) client.LogQuery {
+	container, config, err := serviceLogConfig(exportOpts.client, exportOpts.selector,
+		&exportOpts.ClusterInput, &exportOpts.ServiceInput, exportOpts.ContainerInput)
+	utils.CheckErr(err)
+
+	query.GroupName = config.GroupName
+	if exportOpts.TaskInput == "" {
+		// An empty prefix exports the whole group, e.g. awslogs streams named after container IDs.
+		query.StreamPrefix = config.ContainerPrefix(container)
+		return query
+	}
+
+	if !config.TaskStreams() {
+		utils.CheckErr(fmt.Errorf("the log streams of container '%s' aren't named after tasks, --task can't be used",
+			container))
+	}
+	taskID := exportOpts.TaskInput[strings.LastIndex(exportOpts.TaskInput, "/")+1:]
+	query.StreamNames = []string{config.StreamName(container, taskID)}
+	return query
+}
@@ -1,18 +1,26 @@
 package logs
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/lithammer/fuzzysearch/fuzzy"
-	"github.com/manifoldco/promptui"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/spf13/cobra"
 
 	"going/internal"
 	"going/internal/client"
+	"going/internal/deploy"
 	"going/internal/factory"
+	"going/internal/history"
+	"going/internal/logs"
+	"going/internal/selector"
+	"going/internal/taskdef"
 	"going/internal/utils"
 )
 
@@ -20,122 +28,323 @@ type logOptions struct {
 	ClusterInput   string
 	ServiceInput   string
 	ContainerInput string
+	TaskInput      string
+	TaskPolicy     string
+	Last           bool
+	All            bool
+	AllTasks       bool
 	Minutes        int
-
-	target client.Container
-	client *client.AWSClient
-}
-
-type targetLogConfig struct {
-	GroupName    string
-	StreamPrefix string
+	Since          string
+	Until          string
+	NoFollow       bool
+	Poll           bool
+	Stopped        bool
+	Filter         string
+	Grep           string
+	Invert         bool
+	Group          string
+	StreamPrefix   string
+	Render         string
+	Fields         []string
+
+	target   client.Container
+	client   *client.AWSClient
+	tailer   logs.Tailer
+	grep     *logs.Grep
+	renderer *logs.Renderer
+	selector *selector.Selector
 }
 
 var opts = &logOptions{}
 
-var containerPromptTemplate = &promptui.SelectTemplates{
-	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
-	Active:   fmt.Sprintf("%s {{ .Name | underline }}", promptui.IconSelect),
-	Inactive: "  {{ .Name }}",
-	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .Name | faint }}`, promptui.IconGood),
-	Details: `{{ "Status:" | faint }} {{ .LastStatus }}
-{{ "Health:" | faint }} {{ .Health }}`,
-}
-
 func NewCmdLogs(f *factory.Factory) *cobra.Command {
 	cmd := &cobra.Command{
-		Use: "logs",
+		Use:   "logs [alias]",
+		Short: "Tail the CloudWatch logs of a container in ECS",
+		Long: `Tail the CloudWatch logs of a container in ECS.
+
+An alias from the going config can be given to select the profile, cluster,
+service, and container. Flags take precedence over the alias.`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{factory.AliasAnnotation: "true"},
 		PreRun: func(cmd *cobra.Command, args []string) {
+			if opts.Last {
+				utils.CheckErr(history.UseLast(f))
+			}
 			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			applySettings(f, cmd)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			err := internal.CheckSSOLogin(f)
 			utils.CheckErr(err)
 
-			if opts.ClusterInput == "" {
-				opts.ClusterInput = promptForCluster(f)
+			startTime, endTime, err := timeRange()
+			utils.CheckErr(err)
+
+			if len(opts.Fields) > 0 && opts.Render == logs.StyleRaw {
+				opts.Render = logs.StyleFlat
 			}
+			opts.renderer, err = logs.NewRenderer(opts.Render, opts.Fields, utils.StdoutIsTerminal())
+			utils.CheckErr(err)
 
-			if opts.ServiceInput == "" {
-				opts.ServiceInput = promptForService(f)
+			if opts.StreamPrefix != "" && opts.Group == "" {
+				utils.CheckErr(errors.New("--stream-prefix needs a --group"))
+			}
+			if opts.Invert && opts.Grep == "" {
+				utils.CheckErr(errors.New("--invert needs a --grep expression"))
+			}
+			if opts.Grep != "" {
+				opts.grep, err = logs.NewGrep(opts.Grep, opts.Invert, utils.StdoutIsTerminal())
+				utils.CheckErr(err)
+			}
+
+			handleInterrupt()
+			verb := "Tailing"
+			if follow() {
+				opts.tailer = logs.NewTailer(opts.client, opts.Poll, os.Stderr)
+			} else {
+				verb = "Reading"
+				opts.tailer = logs.TailerFunc(opts.client.ReadLogs)
 			}
 
-			taskARN := getTaskArn(f)
-			logDetails, err := getLogGroup(f, taskARN)
+			printEvent := func(e client.LogEvent) {
+				if message, ok := formatMessage(e.Message); ok {
+					fmt.Printf("%s [%s] %s\n", e.StreamName, e.Timestamp, message)
+				}
+			}
+
+			// The group is given so the service isn't needed.
+			if opts.Group != "" {
+				query := client.LogQuery{
+					GroupName:     opts.Group,
+					StreamPrefix:  opts.StreamPrefix,
+					StartTime:     startTime,
+					EndTime:       endTime,
+					FilterPattern: opts.Filter,
+				}
+				fmt.Printf("%s logs for CloudWatch group \"%s\" with prefix \"%s\"\n\n",
+					verb, query.GroupName, query.StreamPrefix)
+				utils.CheckErr(opts.tailer.TailLogs(query, printEvent))
+				return
+			}
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
 			utils.CheckErr(err)
 
-			startTime := time.Now().Add(-time.Duration(opts.Minutes) * time.Minute)
-			fmt.Printf("Tailing logs for CloudWatch group \"%s\" with prefix \"%s\"\n\n",
-				logDetails.GroupName, logDetails.StreamPrefix)
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
 
-			err = opts.client.TailLogs(logDetails.GroupName, logDetails.StreamPrefix, startTime, func(e client.LogEvent) {
-				fmt.Printf("%s [%s] %s\n", e.StreamName, e.Timestamp, e.Message)
-			})
+			if opts.All {
+				tailService(startTime, endTime)
+				return
+			}
+
+			taskARN, stopped := getTask()
+			logDetails, err := getLogGroup(taskARN)
+			utils.CheckErr(err)
+
+			if stopped != nil {
+				printStopped(*stopped)
+				// Read the stopped task's whole run unless a start was given.
+				if opts.Since == "" && !cmd.Flags().Changed("minutes") && !stopped.CreatedAt.IsZero() {
+					startTime = stopped.CreatedAt
+				}
+				// Its stream won't get new events once it has stopped.
+				if deploy.IsStopped(*stopped) {
+					verb = "Reading"
+					opts.tailer = logs.TailerFunc(opts.client.ReadLogs)
+				}
+			}
+
+			history.RecordTarget(f, "logs", opts.target)
+
+			query := client.LogQuery{
+				GroupName:     logDetails.GroupName,
+				StartTime:     startTime,
+				EndTime:       endTime,
+				FilterPattern: opts.Filter,
+			}
+			stream, err := logs.StreamName(logDetails, opts.target)
+			if err != nil && !opts.AllTasks {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: %s, tailing the container's streams from all tasks\n", err)
+			}
+			if opts.AllTasks || err != nil {
+				// An empty prefix tails the whole group, e.g. awslogs streams named after container IDs.
+				query.StreamPrefix = logDetails.ContainerPrefix(opts.target.Name)
+				fmt.Printf("%s logs for CloudWatch group \"%s\" with prefix \"%s\"\n\n",
+					verb, query.GroupName, query.StreamPrefix)
+			} else {
+				exists, err := opts.client.LogStreamExists(query.GroupName, stream)
+				utils.CheckErr(err)
+				if exists {
+					query.StreamNames = []string{stream}
+				} else {
+					// FilterLogEvents fails for stream names that don't exist,
+					// the name as a prefix matches the stream once it's created.
+					query.StreamPrefix = stream
+					_, _ = fmt.Fprintln(os.Stderr, "The container hasn't logged yet, waiting for its stream to be created.")
+				}
+				fmt.Printf("%s logs for CloudWatch group \"%s\" stream \"%s\"\n\n", verb, query.GroupName, stream)
+			}
+
+			err = opts.tailer.TailLogs(query, printEvent)
 			utils.CheckErr(err)
 		},
 	}
 
 	cmd.Flags().IntVarP(&opts.Minutes, "minutes", "t", 30, "Number of minutes back to filter logs")
+	cmd.Flags().StringVar(&opts.Since, "since", "",
+		"Read logs from a time: RFC3339, a duration back from now like 2h, or a day like 'yesterday 14:00'")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Read logs up to a time in the same formats as --since, implies --no-follow")
+	cmd.Flags().BoolVar(&opts.NoFollow, "no-follow", false, "Print the logs in the time range and exit instead of tailing")
 	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
 	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
 	cmd.Flags().StringVarP(&opts.ContainerInput, "container", "r", "", "The container name")
+	cmd.Flags().StringVar(&opts.TaskInput, "task", "", "The task ID or ARN")
+	cmd.Flags().StringVar(&opts.TaskPolicy, "task-policy", "",
+		"How to pick a task when multiple are running: newest, random, or healthy")
+	cmd.Flags().BoolVar(&opts.Last, "last", false, "Use the most recently used target")
+	cmd.Flags().BoolVarP(&opts.All, "all", "a", false,
+		"Tail every container of every running task of the service, including tasks started while tailing")
+	cmd.Flags().BoolVar(&opts.AllTasks, "all-tasks", false,
+		"Tail the container's logs from all tasks sharing its stream prefix, not only the selected task")
+	cmd.Flags().BoolVar(&opts.Poll, "poll", false,
+		"Poll FilterLogEvents every few seconds instead of streaming with CloudWatch Logs Live Tail")
+	cmd.Flags().StringVar(&opts.Filter, "filter", "",
+		"Only read events matching a CloudWatch Logs filter pattern, e.g. '{ $.level = \"error\" }'")
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "Only print messages matching a regular expression, highlighting the matches")
+	cmd.Flags().BoolVar(&opts.Invert, "invert", false, "Only print messages that don't match --grep")
+	cmd.Flags().StringVar(&opts.Render, "render", logs.StyleRaw,
+		"How to print JSON and logfmt messages: raw, flat (level time msg key=value), or pretty")
+	cmd.Flags().StringSliceVar(&opts.Fields, "fields", nil,
+		"Only print these fields of structured messages, e.g. level,msg,http.status, implies --render flat")
+	cmd.Flags().StringVar(&opts.Group, "group", "",
+		"Tail this log group directly, skipping the lookup of the service's tasks and task definition")
+	cmd.Flags().StringVar(&opts.StreamPrefix, "stream-prefix", "", "Only tail the streams of --group starting with this prefix")
+	cmd.Flags().BoolVar(&opts.Stopped, "stopped", false,
+		"Pick from the recently stopped tasks, showing why they stopped and their exit codes")
+	cmd.MarkFlagsMutuallyExclusive("minutes", "since")
+	cmd.MarkFlagsMutuallyExclusive("all", "task")
+	cmd.MarkFlagsMutuallyExclusive("all", "all-tasks")
+	cmd.MarkFlagsMutuallyExclusive("all", "container")
+	cmd.MarkFlagsMutuallyExclusive("all", "stopped")
+	for _, flag := range []string{"all", "all-tasks", "task", "last", "stopped"} {
+		cmd.MarkFlagsMutuallyExclusive("group", flag)
+	}
+
+	cmd.AddCommand(NewCmdQuery(f))
+	cmd.AddCommand(NewCmdExport(f))
 
 	return cmd
 }
 
-func promptForCluster(f *factory.Factory) string {
-	c, err := opts.client.ListClusters()
-	utils.CheckErr(err)
-
-	var clusters []string
-	for _, cluster := range c {
-		clusters = append(clusters, cluster.Name)
+// applySettings fills in any flags that weren't given with values from the going config.
+func applySettings(f *factory.Factory, cmd *cobra.Command) {
+	s := f.Settings()
+	if opts.ClusterInput == "" {
+		opts.ClusterInput = s.Cluster
+	}
+	if opts.ServiceInput == "" {
+		opts.ServiceInput = s.Service
+	}
+	if opts.ContainerInput == "" {
+		opts.ContainerInput = s.Container
+	}
+	if !cmd.Flags().Changed("minutes") && s.LogMinutes > 0 {
+		opts.Minutes = s.LogMinutes
 	}
+	if !cmd.Flags().Changed("render") && s.LogRender != "" {
+		opts.Render = s.LogRender
+	}
+}
 
-	return f.Prompt.Select("Select a cluster", clusters)
+// formatMessage applies --grep and --render to a message, false if --grep
+// doesn't select it. Matches are only highlighted in raw messages, --grep
+// always matches the message as it was logged.
+func formatMessage(message string) (string, bool) {
+	highlighted, ok := opts.grep.Apply(message)
+	if !ok {
+		return "", false
+	}
+	if opts.renderer.Style == logs.StyleRaw {
+		return highlighted, true
+	}
+	return opts.renderer.Render(message), true
 }
 
-func promptForService(f *factory.Factory) string {
-	s, err := opts.client.ListServices(opts.ClusterInput)
-	utils.CheckErr(err)
+// timeRange returns the window of events to read from --since and --until,
+// or --minutes back from now. The end is zero when there is no --until.
+func timeRange() (time.Time, time.Time, error) {
+	now := time.Now()
+	start := now.Add(-time.Duration(opts.Minutes) * time.Minute)
+	if opts.Since != "" {
+		var err error
+		start, err = logs.ParseTime(opts.Since, now)
+		if err != nil {
+			return start, time.Time{}, fmt.Errorf("invalid --since, %w", err)
+		}
+	}
 
-	var services []string
-	for _, service := range s {
-		services = append(services, service.Name)
+	if opts.Until == "" {
+		return start, time.Time{}, nil
 	}
+	end, err := logs.ParseTime(opts.Until, now)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid --until, %w", err)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("--until %s isn't after the start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	return start, end, nil
+}
 
-	return f.Prompt.Select("Select a service", services)
+// follow checks if new events should be tailed, a window ending at --until
+// is only read once.
+func follow() bool {
+	return !opts.NoFollow && opts.Until == ""
 }
 
-func getTaskArn(f *factory.Factory) string {
-	t, err := opts.client.ListTasks(opts.ClusterInput, opts.ServiceInput)
-	utils.CheckErr(err)
+// getTask returns the ARN of the task to read the logs of. Recently stopped
+// tasks are picked from with --stopped, when no tasks are running, or when
+// --task isn't running, and are also returned.
+func getTask() (string, *client.Task) {
+	if !opts.Stopped {
+		taskARN, err := opts.selector.Task(opts.ClusterInput, opts.ServiceInput, opts.TaskInput, opts.TaskPolicy)
+		switch {
+		case errors.Is(err, selector.ErrNoTasks):
+			_, _ = fmt.Fprintln(os.Stderr, "No tasks running, picking from the recently stopped tasks.")
+		case errors.Is(err, selector.ErrTaskNotRunning):
+		default:
+			utils.CheckErr(err)
+			return taskARN, nil
+		}
+	}
 
-	switch len(t) {
-	case 0:
-		fmt.Println("No tasks running. We need a task ARN to get a task definition to get the logging information.")
+	task, err := opts.selector.StoppedTask(opts.ClusterInput, opts.ServiceInput, opts.TaskInput, opts.TaskPolicy)
+	if errors.Is(err, selector.ErrNoStoppedTasks) {
+		fmt.Println("No tasks running or recently stopped. We need a task ARN to get a task definition to get the " +
+			"logging information.")
 		os.Exit(1)
-		return "" // won't reach
-	case 1:
-		return t[0]
-	default:
-		return f.Prompt.Select("Multiple tasks running, please select one", t)
 	}
+	utils.CheckErr(err)
+	return task.ARN, &task
 }
 
-func getLogGroup(f *factory.Factory, taskARN string) (targetLogConfig, error) {
-	var details client.Container
-	var config targetLogConfig
+// printStopped describes why the task stopped and how its containers exited.
+func printStopped(t client.Task) {
+	st := selector.NewStoppedTask(t)
+	fmt.Printf("Task %s %s: %s", st.ID, st.Stopped, t.StoppedReason)
+	if t.StopCode != "" {
+		fmt.Printf(" (%s)", t.StopCode)
+	}
+	fmt.Printf("\nExit codes: %s\n", st.ExitCodes)
+}
 
-	if opts.ContainerInput == "" {
-		containers, err := opts.client.DescribeContainers(opts.ClusterInput, taskARN)
-		utils.CheckErr(err)
-		i := f.Prompt.CustomSelect("Select a container", containers, containerPromptTemplate, containerSearch(containers))
-		details = containers[i]
-	} else {
-		c, err := opts.client.DescribeContainer(opts.ClusterInput, taskARN, opts.ContainerInput)
-		utils.CheckErr(err)
-		details = c
+func getLogGroup(taskARN string) (taskdef.LogConfig, error) {
+	details, err := opts.selector.Container(opts.ClusterInput, taskARN, opts.ContainerInput)
+	if err != nil {
+		return taskdef.LogConfig{}, err
 	}
 
 	opts.target = details
@@ -145,31 +354,223 @@ func getLogGroup(f *factory.Factory, taskARN string) (targetLogConfig, error) {
 
 	for _, container := range definition.ContainerDefinitions {
 		if aws.ToString(container.Name) == details.Name {
-			if container.LogConfiguration == nil {
-				return config, fmt.Errorf("no log configuration found")
+			return taskdef.CloudWatchLogs(container)
+		}
+	}
+
+	return taskdef.LogConfig{}, fmt.Errorf("no container '%s' in task definition", details.Name)
+}
+
+// serviceRefresh is how often the tasks of the service are listed again
+// while tailing, so tasks replacing stopped ones are tailed too.
+const serviceRefresh = 30 * time.Second
+
+// tailService tails the logs of every container of the service's running
+// tasks together, labelling each event with its task and container. Without
+// following, the events in the time range are printed in order instead.
+// A container failing to tail is reported without stopping the others.
+func tailService(startTime time.Time, endTime time.Time) {
+	finder := &serviceStreams{
+		definitions: map[string]*types.TaskDefinition{},
+		known:       map[string]bool{},
+		warned:      map[string]bool{},
+	}
+	tasks, streams, err := finder.next()
+	utils.CheckErr(err)
+	if len(tasks) == 0 {
+		fmt.Println("No tasks running. We need running tasks to know which log streams to tail.")
+		os.Exit(1)
+	}
+	if len(streams) == 0 {
+		utils.CheckErr(fmt.Errorf("none of the containers of service '%s' log to CloudWatch", opts.ServiceInput))
+	}
+
+	var mu sync.Mutex
+	byName := map[string]logs.Stream{}
+	labeler := &logs.Labeler{Color: utils.StdoutIsTerminal()}
+	printEvent := func(e client.LogEvent) {
+		if message, ok := formatMessage(e.Message); ok {
+			fmt.Printf("%s [%s] %s\n", labeler.Label(byName[e.GroupName+":"+e.StreamName]), e.Timestamp, message)
+		}
+	}
+	queries := func(streams []logs.Stream, startTime time.Time) []client.LogQuery {
+		for _, s := range streams {
+			byName[s.GroupName+":"+s.Name] = s
+		}
+		queries := logs.Queries(streams)
+		for i := range queries {
+			queries[i].StartTime = startTime
+			queries[i].EndTime = endTime
+			queries[i].FilterPattern = opts.Filter
+		}
+		return queries
+	}
+
+	if !follow() {
+		fmt.Printf("Reading %d containers of %d tasks of service \"%s\"\n\n", len(streams), len(tasks), opts.ServiceInput)
+
+		// The queries are read one after the other so the events are sorted to interleave them.
+		var events []client.LogEvent
+		for _, q := range queries(streams, startTime) {
+			err := opts.tailer.TailLogs(q, func(e client.LogEvent) {
+				events = append(events, e)
+			})
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "Warning: failed to read %s, %s\n", describeQuery(q), err)
 			}
-			if group, ok := container.LogConfiguration.Options["awslogs-group"]; ok {
-				config.GroupName = group
-			} else {
-				// We have to have a log group
-				return config, fmt.Errorf("no log group found")
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		})
+		for _, e := range events {
+			printEvent(e)
+		}
+		return
+	}
+
+	fmt.Printf("Tailing %d containers of %d tasks of service \"%s\"\n\n", len(streams), len(tasks), opts.ServiceInput)
+
+	errc := make(chan error)
+	active := 0
+	tail := func(streams []logs.Stream, startTime time.Time) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, q := range queries(streams, startTime) {
+			active++
+			go func(q client.LogQuery) {
+				err := opts.tailer.TailLogs(q, func(e client.LogEvent) {
+					mu.Lock()
+					defer mu.Unlock()
+					printEvent(e)
+				})
+				if err != nil {
+					err = fmt.Errorf("%s, %w", describeQuery(q), err)
+				}
+				errc <- err
+			}(q)
+		}
+	}
+	tail(streams, startTime)
+
+	refresh := time.NewTicker(serviceRefresh)
+	defer refresh.Stop()
+	for {
+		select {
+		case err := <-errc:
+			mu.Lock()
+			active--
+			remaining := active
+			mu.Unlock()
+			if err == nil {
+				continue
+			}
+			if remaining == 0 {
+				utils.CheckErr(err)
+			}
+			_, _ = fmt.Fprintln(os.Stderr, "Warning: stopped tailing", err)
+		case <-refresh.C:
+			tasks, streams, err := finder.next()
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "Warning: failed to look for new tasks,", err)
+				continue
+			}
+			if len(streams) == 0 {
+				continue
 			}
 
-			if prefix, ok := container.LogConfiguration.Options["awslogs-stream-prefix"]; ok {
-				config.StreamPrefix = prefix
+			// New streams belong to tasks started since the last listing.
+			since := time.Now()
+			for _, t := range tasks {
+				for _, s := range streams {
+					if s.TaskID == t.ID() && t.CreatedAt.Before(since) {
+						since = t.CreatedAt
+					}
+				}
 			}
+			if since.Before(startTime) {
+				since = startTime
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Tailing %d more containers of new tasks\n", len(streams))
+			tail(streams, since)
 		}
 	}
+}
 
-	return config, nil
+// serviceStreams finds the log streams of the service's running tasks.
+type serviceStreams struct {
+	definitions map[string]*types.TaskDefinition
+	// known are the streams already returned.
+	known map[string]bool
+	// warned are the errors already reported.
+	warned map[string]bool
 }
 
-func containerSearch(containers []client.Container) func(input string, index int) bool {
-	return func(input string, index int) bool {
-		item := containers[index]
-		if fuzzy.MatchFold(input, item.Name) {
-			return true
+// next returns the running tasks and the streams of their containers that
+// weren't returned before, marking those that don't exist yet.
+func (s *serviceStreams) next() ([]client.Task, []logs.Stream, error) {
+	taskARNs, err := opts.client.ListTasks(opts.ClusterInput, opts.ServiceInput)
+	if err != nil || len(taskARNs) == 0 {
+		return nil, nil, err
+	}
+
+	tasks, err := opts.client.DescribeTasks(opts.ClusterInput, taskARNs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range tasks {
+		if _, ok := s.definitions[t.DefinitionARN]; ok {
+			continue
+		}
+		s.definitions[t.DefinitionARN], err = opts.client.DescribeTaskDefinition(t.DefinitionARN)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	streams, errs := logs.Streams(tasks, s.definitions)
+	for _, err := range errs {
+		if !s.warned[err.Error()] {
+			s.warned[err.Error()] = true
+			_, _ = fmt.Fprintln(os.Stderr, "Warning: skipping", err)
+		}
+	}
+
+	var added []logs.Stream
+	for _, stream := range streams {
+		key := stream.GroupName + ":" + stream.Name
+		if s.known[key] {
+			continue
+		}
+		exists, err := opts.client.LogStreamExists(stream.GroupName, stream.Name)
+		if err != nil {
+			return nil, nil, err
 		}
-		return false
+		stream.Missing = !exists
+		s.known[key] = true
+		added = append(added, stream)
 	}
+	return tasks, added, nil
+}
+
+// describeQuery names the group and streams of a query for messages.
+func describeQuery(q client.LogQuery) string {
+	if len(q.StreamNames) == 1 {
+		return fmt.Sprintf("group \"%s\" stream \"%s\"", q.GroupName, q.StreamNames[0])
+	}
+	if len(q.StreamNames) > 1 {
+		return fmt.Sprintf("group \"%s\" streams %s and %d more", q.GroupName, q.StreamNames[0], len(q.StreamNames)-1)
+	}
+	return fmt.Sprintf("group \"%s\" prefix \"%s\"", q.GroupName, q.StreamPrefix)
+}
+
+// handleInterrupt exits quietly on ctrl+c. Not really necessary but it makes
+// the console look nice when exiting.
+func handleInterrupt() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	go func() {
+		<-sigChan
+		fmt.Println("\nCaught ctrl+c, quit!")
+		os.Exit(0)
+	}()
 }
@@ -0,0 +1,220 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/goingconfig"
+	"going/internal/logs"
+	"going/internal/output"
+	"going/internal/selector"
+	"going/internal/taskdef"
+	"going/internal/utils"
+)
+
+type queryOptions struct {
+	ClusterInput   string
+	ServiceInput   string
+	ContainerInput string
+	Groups         []string
+	Since          string
+	Until          string
+	Limit          int32
+	List           bool
+	Timeout        time.Duration
+	Interval       time.Duration
+	Output         output.Options
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+// minQueryInterval is the shortest time to wait between polls for a query's results.
+const minQueryInterval = 500 * time.Millisecond
+
+var queryOpts = &queryOptions{}
+
+func NewCmdQuery(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [QUERY | SAVED_QUERY]",
+		Short: "Run a CloudWatch Logs Insights query",
+		Long: `Run a CloudWatch Logs Insights query against the log group of a container.
+
+The log group is read from the awslogs or FireLens configuration of the
+container in the service's task definition, use --group to query other groups
+instead. The query is either Logs Insights query syntax or the name of a query
+saved under queries in the going config, list them with --list. A query that
+runs past --timeout or is interrupted with ctrl+c is stopped.`,
+		Example: `  going logs query -s api 'filter @message like /error/ | stats count(*) by bin(5m)'
+  going logs query -s api errors-by-path --since 6h -o csv`,
+		Args: cobra.MaximumNArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			queryOpts.client = client.New(f.Context, f.Config())
+			queryOpts.selector = selector.New(f, queryOpts.client)
+			s := f.Settings()
+			if queryOpts.ClusterInput == "" {
+				queryOpts.ClusterInput = s.Cluster
+			}
+			if queryOpts.ServiceInput == "" {
+				queryOpts.ServiceInput = s.Service
+			}
+			if queryOpts.ContainerInput == "" {
+				queryOpts.ContainerInput = s.Container
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if queryOpts.List {
+				listQueries(f.GoingConfig.Queries)
+				return
+			}
+			if len(args) == 0 {
+				utils.CheckErr(fmt.Errorf("a query or the name of a saved query is required"))
+			}
+			// Polling without a pause gets throttled right away.
+			if queryOpts.Interval < minQueryInterval {
+				utils.CheckErr(fmt.Errorf("--interval %s is too short, use at least %s", queryOpts.Interval, minQueryInterval))
+			}
+
+			queryString := args[0]
+			if saved, ok := f.GoingConfig.Queries[queryString]; ok {
+				queryString = saved
+			}
+
+			now := time.Now()
+			startTime, err := logs.ParseTime(queryOpts.Since, now)
+			utils.CheckErr(err)
+			endTime := now
+			if queryOpts.Until != "" {
+				endTime, err = logs.ParseTime(queryOpts.Until, now)
+				utils.CheckErr(err)
+			}
+			if !endTime.After(startTime) {
+				utils.CheckErr(fmt.Errorf("--until %s isn't after --since %s",
+					endTime.Format(time.RFC3339), startTime.Format(time.RFC3339)))
+			}
+
+			err = internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			groups := queryOpts.Groups
+			if len(groups) == 0 {
+				_, config, err := serviceLogConfig(queryOpts.client, queryOpts.selector,
+					&queryOpts.ClusterInput, &queryOpts.ServiceInput, queryOpts.ContainerInput)
+				utils.CheckErr(err)
+				groups = []string{config.GroupName}
+			}
+
+			_, _ = fmt.Fprintf(os.Stderr, "Querying %v from %s to %s\n", groups,
+				startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
+
+			// The query is stopped on ctrl+c rather than left running in CloudWatch.
+			interrupts := make(chan os.Signal, 1)
+			signal.Notify(interrupts, os.Interrupt)
+			runner := &logs.QueryRunner{
+				Client:    queryOpts.client,
+				Interval:  queryOpts.Interval,
+				Timeout:   queryOpts.Timeout,
+				Interrupt: interrupts,
+			}
+			results, err := runner.Run(client.InsightsQuery{
+				GroupNames: groups,
+				Query:      queryString,
+				StartTime:  startTime,
+				EndTime:    endTime,
+				Limit:      queryOpts.Limit,
+			})
+			signal.Stop(interrupts)
+			if errors.Is(err, logs.ErrQueryInterrupted) {
+				_, _ = fmt.Fprintf(os.Stderr, "\n%s\n", err)
+				os.Exit(130)
+			}
+			utils.CheckErr(err)
+
+			err = output.Print(os.Stdout, queryOpts.Output, results.Rows, logs.QueryColumns(results.Fields))
+			utils.CheckErr(err)
+
+			_, _ = fmt.Fprintf(os.Stderr, "%d rows, %.0f records matched, %.0f records scanned\n",
+				len(results.Rows), results.RecordsMatched, results.RecordsScanned)
+		},
+	}
+
+	cmd.Flags().StringVarP(&queryOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&queryOpts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVarP(&queryOpts.ContainerInput, "container", "r", "", "The container name")
+	cmd.Flags().StringSliceVar(&queryOpts.Groups, "group", nil,
+		"Query these log groups instead of the container's, can be repeated")
+	cmd.Flags().StringVar(&queryOpts.Since, "since", "1h",
+		"Query from a time: RFC3339, a duration back from now like 6h, or a day like 'yesterday 14:00'")
+	cmd.Flags().StringVar(&queryOpts.Until, "until", "", "Query up to a time in the same formats as --since (default now)")
+	cmd.Flags().Int32Var(&queryOpts.Limit, "limit", 0, "The most rows to return, the query's own limit or 1000 by default")
+	cmd.Flags().BoolVar(&queryOpts.List, "list", false, "List the saved queries from the going config")
+	cmd.Flags().DurationVar(&queryOpts.Timeout, "timeout", 5*time.Minute, "How long to wait for the query to complete")
+	cmd.Flags().DurationVar(&queryOpts.Interval, "interval", time.Second, "How often to poll for the query's results, at least 500ms")
+	output.AddFlags(cmd, &queryOpts.Output)
+
+	return cmd
+}
+
+// serviceLogConfig returns the name and log configuration of the container
+// in the service's task definition, selecting the cluster and service if they
+// aren't given. Unlike tailing, no task needs to be running.
+func serviceLogConfig(c *client.AWSClient, s *selector.Selector, cluster *string, service *string,
+	containerInput string) (string, taskdef.LogConfig, error) {
+	var err error
+	*cluster, err = s.Cluster(*cluster)
+	if err != nil {
+		return "", taskdef.LogConfig{}, err
+	}
+
+	*service, err = s.Service(*cluster, *service)
+	if err != nil {
+		return "", taskdef.LogConfig{}, err
+	}
+
+	svc, err := c.DescribeService(*cluster, *service)
+	if err != nil {
+		return "", taskdef.LogConfig{}, err
+	}
+
+	definition, err := c.DescribeTaskDefinition(aws.ToString(svc.TaskDefinition))
+	if err != nil {
+		return "", taskdef.LogConfig{}, err
+	}
+
+	container, err := s.ContainerDefinition(definition, containerInput)
+	if err != nil {
+		return "", taskdef.LogConfig{}, err
+	}
+
+	config, err := taskdef.CloudWatchLogs(container)
+	return aws.ToString(container.Name), config, err
+}
+
+// listQueries prints the names and queries of the saved queries.
+func listQueries(queries map[string]string) {
+	if len(queries) == 0 {
+		fmt.Printf("No saved queries, add them under queries in %s\n", goingconfig.Filename())
+		return
+	}
+
+	var names []string
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		query := strings.ReplaceAll(strings.TrimSpace(queries[name]), "\n", "\n  ")
+		fmt.Printf("%s:\n  %s\n", name, query)
+	}
+}
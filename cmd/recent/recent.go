@@ -0,0 +1,85 @@
+package recent
+
+import (
+	"fmt"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"going/internal/factory"
+	"going/internal/history"
+	"going/internal/utils"
+)
+
+var runInput string
+
+var recentPromptTemplate = &promptui.SelectTemplates{
+	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
+	Active:   fmt.Sprintf(`%s {{ .Service | underline }}/{{ .Container | underline }} {{ .Ago | faint }}`, promptui.IconSelect),
+	Inactive: `  {{ .Service }}/{{ .Container }} {{ .Ago | faint }}`,
+	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .Service | faint }}/{{ .Container | faint }}`, promptui.IconGood),
+	Details: `{{ "Command:" | faint }} {{ .Command }}
+{{ "Profile:" | faint }} {{ .Profile }}
+{{ "Region:" | faint }} {{ .Region }}
+{{ "Cluster:" | faint }} {{ .Cluster }}`,
+}
+
+// NewCmdRecent creates the recent command. The commands are the ones a recent
+// target can be run with, they are looked up by name.
+func NewCmdRecent(f *factory.Factory, commands ...*cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "Select a recently used target and connect to it again",
+		Long: `Select a recently used target and connect to it again.
+
+Targets are recorded by the shell and logs commands, the most recent are shown
+first. By default the command last used with the target is run.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			h, err := history.Read(history.Filename())
+			utils.CheckErr(err)
+			if len(h.Entries) == 0 {
+				utils.CheckErr(fmt.Errorf("no recent targets in %s", history.Filename()))
+			}
+
+			i, err := f.Prompt.CustomSelect("Select a recent target", h.Entries, recentPromptTemplate, recentSearch(h.Entries))
+			utils.CheckErr(err)
+			e := h.Entries[i]
+
+			name := runInput
+			if name == "" {
+				name = e.Command
+			}
+
+			var target *cobra.Command
+			for _, c := range commands {
+				if c.Name() == name {
+					target = c
+				}
+			}
+			if target == nil {
+				utils.CheckErr(fmt.Errorf("unknown command '%s'", name))
+			}
+
+			f.Overrides = e.Settings()
+			if target.PreRun != nil {
+				target.PreRun(target, nil)
+			}
+			target.Run(target, nil)
+		},
+	}
+
+	cmd.Flags().StringVar(&runInput, "run", "", "The command to run for the target, shell or logs")
+
+	return cmd
+}
+
+func recentSearch(entries []history.Entry) func(input string, index int) bool {
+	return func(input string, index int) bool {
+		item := entries[index]
+		if fuzzy.MatchFold(input, item.Service+"/"+item.Container) {
+			return true
+		}
+		return false
+	}
+}
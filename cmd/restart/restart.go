@@ -0,0 +1,123 @@
+package restart
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+
+	deploycmd "going/cmd/deploy"
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type restartOptions struct {
+	ClusterInput string
+	ServiceInput string
+	OneByOne     bool
+	Watch        bool
+	Timeout      time.Duration
+	Interval     time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &restartOptions{}
+
+func NewCmdRestart(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restart",
+		Short: "Restart all the tasks of a service",
+		Long: `Restart all the tasks of a service.
+
+By default a new deployment is forced which replaces the tasks using the
+service's deployment configuration. Use --watch to follow the deployment until
+it completes.
+
+With --one-by-one each task is stopped individually and the next task is only
+stopped once its replacement is running and healthy. It waits for each task so
+--watch isn't needed, and --timeout applies to each replacement instead of the
+whole deployment.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			fmt.Printf("cluster: \"%s\" service: \"%s\"\n", opts.ClusterInput, opts.ServiceInput)
+			yes, err := f.Prompt.YesNoPrompt("Restart all tasks of the above service")
+			utils.CheckErr(err)
+			if !yes {
+				os.Exit(0)
+			}
+
+			if opts.OneByOne {
+				r := &deploy.RollingRestart{
+					Client:   opts.client,
+					Cluster:  opts.ClusterInput,
+					Service:  opts.ServiceInput,
+					Interval: opts.Interval,
+					Timeout:  opts.Timeout,
+					Out:      os.Stdout,
+				}
+				utils.CheckErr(r.Run())
+				return
+			}
+
+			err = opts.client.UpdateService(&ecs.UpdateServiceInput{
+				Cluster:            aws.String(opts.ClusterInput),
+				Service:            aws.String(opts.ServiceInput),
+				ForceNewDeployment: true,
+			})
+			utils.CheckErr(err)
+			fmt.Println("Forced a new deployment of the service.")
+
+			if opts.Watch {
+				w := &deploy.Watcher{
+					Client:   opts.client,
+					Cluster:  opts.ClusterInput,
+					Service:  opts.ServiceInput,
+					Interval: opts.Interval,
+					Timeout:  opts.Timeout,
+					Out:      os.Stdout,
+				}
+				utils.CheckErr(w.Watch())
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Watch the deployment until it completes")
+	cmd.Flags().BoolVar(&opts.OneByOne, "one-by-one", false,
+		"Stop the tasks one at a time, waiting for each replacement to be healthy")
+	deploycmd.AddWatchFlags(cmd, &opts.Timeout, &opts.Interval)
+	cmd.Flag("timeout").Usage = "How long to wait for the deployment to complete, " +
+		"or with --one-by-one for each replacement task to be healthy"
+	cmd.MarkFlagsMutuallyExclusive("watch", "one-by-one")
+
+	return cmd
+}
@@ -0,0 +1,227 @@
+package rollback
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	deploycmd "going/cmd/deploy"
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/taskdef"
+	"going/internal/utils"
+)
+
+type rollbackOptions struct {
+	ClusterInput  string
+	ServiceInput  string
+	RevisionInput int32
+	Revisions     int
+	Watch         bool
+	Timeout       time.Duration
+	Interval      time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+// revision is a task definition revision that can be rolled back to.
+type revision struct {
+	Name       string
+	Number     int32
+	Images     string
+	Registered string
+
+	definition *types.TaskDefinition
+}
+
+var opts = &rollbackOptions{}
+
+var revisionPromptTemplate = &promptui.SelectTemplates{
+	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
+	Active:   fmt.Sprintf("%s {{ .Name | underline }} {{ .Registered | faint }}", promptui.IconSelect),
+	Inactive: "  {{ .Name }} {{ .Registered | faint }}",
+	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .Name | faint }}`, promptui.IconGood),
+	Details:  `{{ "Images:" | faint }} {{ .Images }}`,
+}
+
+func NewCmdRollback(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll a service back to a previous task definition revision",
+		Long: `Roll a service back to a previous task definition revision.
+
+The recent revisions of the service's task definition family are listed with
+their images, or any revision of the family can be given with --revision. After
+picking one the differences from the current revision are shown and, once
+confirmed, the service is updated to use it.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			service, err := opts.client.DescribeService(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			current, err := opts.client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+			utils.CheckErr(err)
+
+			target := selectRevision(f, current)
+
+			fmt.Printf("Changes from %s to %s:\n", taskdef.Name(current), target.Name)
+			changes := taskdef.Diff(current, target.definition)
+			if len(changes) == 0 {
+				fmt.Println("  none")
+			}
+			for _, c := range changes {
+				fmt.Printf("  %s\n", c)
+			}
+
+			yes, err := f.Prompt.YesNoPrompt(fmt.Sprintf("Update service %s to %s", opts.ServiceInput, target.Name))
+			utils.CheckErr(err)
+			if !yes {
+				os.Exit(0)
+			}
+
+			err = opts.client.UpdateService(&ecs.UpdateServiceInput{
+				Cluster:        aws.String(opts.ClusterInput),
+				Service:        aws.String(opts.ServiceInput),
+				TaskDefinition: target.definition.TaskDefinitionArn,
+			})
+			utils.CheckErr(err)
+			fmt.Printf("Updated service %s to %s.\n", opts.ServiceInput, target.Name)
+
+			if opts.Watch {
+				w := &deploy.Watcher{
+					Client:   opts.client,
+					Cluster:  opts.ClusterInput,
+					Service:  opts.ServiceInput,
+					Interval: opts.Interval,
+					Timeout:  opts.Timeout,
+					Out:      os.Stdout,
+				}
+				utils.CheckErr(w.Watch())
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().Int32Var(&opts.RevisionInput, "revision", 0, "The revision number to roll back to, any revision of the family")
+	cmd.Flags().IntVar(&opts.Revisions, "revisions", 10, "Number of recent revisions to choose from")
+	cmd.Flags().BoolVarP(&opts.Watch, "watch", "w", false, "Watch the deployment until it completes")
+	deploycmd.AddWatchFlags(cmd, &opts.Timeout, &opts.Interval)
+
+	return cmd
+}
+
+// listRevisions returns the recent revisions of the family other than the current one.
+func listRevisions(current *types.TaskDefinition) ([]revision, error) {
+	// One extra since the current revision is skipped.
+	arns, err := opts.client.ListTaskDefinitions(aws.ToString(current.Family), opts.Revisions+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []revision
+	for _, arn := range arns {
+		if arn == aws.ToString(current.TaskDefinitionArn) || len(revisions) >= opts.Revisions {
+			continue
+		}
+
+		definition, err := opts.client.DescribeTaskDefinition(arn)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, newRevision(definition))
+	}
+
+	return revisions, nil
+}
+
+func newRevision(definition *types.TaskDefinition) revision {
+	registered := ""
+	if definition.RegisteredAt != nil {
+		registered = definition.RegisteredAt.Local().Format(time.DateTime)
+	}
+
+	return revision{
+		Name:       taskdef.Name(definition),
+		Number:     definition.Revision,
+		Images:     strings.Join(taskdef.Images(definition), ", "),
+		Registered: registered,
+		definition: definition,
+	}
+}
+
+// selectRevision returns the revision from the flag, which can be any revision
+// of the family, or prompts for one of the recent revisions.
+func selectRevision(f *factory.Factory, current *types.TaskDefinition) revision {
+	family := aws.ToString(current.Family)
+	if opts.RevisionInput != 0 {
+		if opts.RevisionInput == current.Revision {
+			utils.CheckErr(fmt.Errorf("service %s already uses %s", opts.ServiceInput, taskdef.Name(current)))
+		}
+		definition, err := opts.client.DescribeTaskDefinition(fmt.Sprintf("%s:%d", family, opts.RevisionInput))
+		utils.CheckErr(err)
+		return newRevision(definition)
+	}
+
+	revisions, err := listRevisions(current)
+	utils.CheckErr(err)
+	if len(revisions) == 0 {
+		utils.CheckErr(fmt.Errorf("no other active revisions of task definition family '%s'", family))
+	}
+
+	var names []string
+	for _, r := range revisions {
+		names = append(names, r.Name)
+	}
+
+	if f.NonInteractive {
+		utils.CheckErr(fmt.Errorf("no revision given and %w, use --revision with one of: %s",
+			utils.ErrNonInteractive, strings.Join(names, ", ")))
+	}
+
+	i, err := f.Prompt.CustomSelect("Select a revision to roll back to", revisions, revisionPromptTemplate,
+		revisionSearch(revisions))
+	utils.CheckErr(err)
+	return revisions[i]
+}
+
+func revisionSearch(revisions []revision) func(input string, index int) bool {
+	return func(input string, index int) bool {
+		item := revisions[index]
+		if fuzzy.MatchFold(input, item.Name+" "+item.Images) {
+			return true
+		}
+		return false
+	}
+}
@@ -3,10 +3,24 @@ package cmd
 import (
 	"github.com/spf13/cobra"
 
+	"going/cmd/debug"
+	"going/cmd/deploy"
+	"going/cmd/doctor"
+	"going/cmd/ecs"
+	"going/cmd/env"
+	"going/cmd/exec"
 	"going/cmd/logs"
+	"going/cmd/recent"
+	"going/cmd/restart"
+	"going/cmd/rollback"
+	"going/cmd/runtask"
+	"going/cmd/scale"
 	"going/cmd/shell"
 	"going/cmd/sso"
+	"going/cmd/status"
+	"going/cmd/taskdef"
 	"going/internal/factory"
+	"going/internal/utils"
 )
 
 func NewCmdRoot(version string) *cobra.Command {
@@ -18,18 +32,39 @@ func NewCmdRoot(version string) *cobra.Command {
 		Version: version,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			f.Context = cmd.Context()
-			if f.ProfileName == "" {
-				p := f.Prompt.Select("Select a profile", f.LocalAWSConfig.ProfileNames())
-				f.ProfileName = p
+			f.ConfigurePrompt()
+			if cmd.Annotations[factory.AliasAnnotation] != "" && len(args) > 0 {
+				err := f.UseAlias(args[0])
+				utils.CheckErr(err)
 			}
 		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&f.ProfileName, "profile", "p", "", "The AWS profile to use")
+	cmd.PersistentFlags().StringVar(&f.Region, "region", "", "The AWS region to use")
+	cmd.PersistentFlags().BoolVar(&f.NonInteractive, "non-interactive", false,
+		"Never prompt, error when a choice is missing (default when stdin isn't a terminal)")
+	cmd.PersistentFlags().BoolVarP(&f.AssumeYes, "yes", "y", false, "Answer yes to all confirmations")
 
-	cmd.AddCommand(shell.NewCmdShell(f))
+	shellCmd := shell.NewCmdShell(f)
+	logsCmd := logs.NewCmdLogs(f)
+
+	cmd.AddCommand(shellCmd)
 	cmd.AddCommand(sso.NewCmdSSO(f))
-	cmd.AddCommand(logs.NewCmdLogs(f))
+	cmd.AddCommand(logsCmd)
+	cmd.AddCommand(recent.NewCmdRecent(f, shellCmd, logsCmd))
+	cmd.AddCommand(ecs.NewCmdECS(f))
+	cmd.AddCommand(status.NewCmdStatus(f))
+	cmd.AddCommand(deploy.NewCmdDeploy(f))
+	cmd.AddCommand(restart.NewCmdRestart(f))
+	cmd.AddCommand(scale.NewCmdScale(f))
+	cmd.AddCommand(rollback.NewCmdRollback(f))
+	cmd.AddCommand(taskdef.NewCmdTaskdef(f))
+	cmd.AddCommand(env.NewCmdEnv(f))
+	cmd.AddCommand(runtask.NewCmdRunTask(f))
+	cmd.AddCommand(debug.NewCmdDebug(f))
+	cmd.AddCommand(exec.NewCmdExec(f))
+	cmd.AddCommand(doctor.NewCmdDoctor(f))
 
 	return cmd
 }
@@ -0,0 +1,191 @@
+package runtask
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/factory"
+	"going/internal/logs"
+	"going/internal/selector"
+	"going/internal/taskdef"
+	"going/internal/utils"
+)
+
+// logsDrainTime is how long to keep tailing after the task stops so the last
+// log events are printed.
+const logsDrainTime = 10 * time.Second
+
+type runTaskOptions struct {
+	ClusterInput   string
+	ServiceInput   string
+	ContainerInput string
+	Environment    []string
+	Wait           bool
+	Logs           bool
+	Timeout        time.Duration
+	Interval       time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &runTaskOptions{}
+
+func NewCmdRunTask(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run-task [-- command...]",
+		Short: "Run a one-off task with a service's configuration",
+		Long: `Run a one-off task with a service's configuration.
+
+The task uses the service's task definition, network configuration, and launch
+type or capacity provider strategy. The command and environment of one
+container can be overridden, the command is given after --.
+
+With --wait the command waits for the task to stop and exits with the
+container's exit code. --logs also tails the container's logs while waiting.`,
+		Example: `  going run-task -c main -s api -r web -- ./manage.py migrate
+  going run-task -c main -s worker -e BATCH_SIZE=100 --logs -- ./backfill`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+			if opts.ContainerInput == "" {
+				opts.ContainerInput = s.Container
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			environment, err := parseEnvironment(opts.Environment)
+			utils.CheckErr(err)
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			service, err := opts.client.DescribeServiceWithTags(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			definition, err := opts.client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+			utils.CheckErr(err)
+
+			container, err := opts.selector.ContainerDefinition(definition, opts.ContainerInput)
+			utils.CheckErr(err)
+			containerName := aws.ToString(container.Name)
+
+			if deploy.IsProduction(service.Tags) {
+				label := fmt.Sprintf("Service %s is tagged as production, run a task of %s", opts.ServiceInput,
+					taskdef.Name(definition))
+				yes, err := f.Prompt.YesNoPrompt(label)
+				utils.CheckErr(err)
+				if !yes {
+					os.Exit(0)
+				}
+			}
+
+			// Resolved before starting the task so one that can't be tailed
+			// isn't left running untracked.
+			var logConfig taskdef.LogConfig
+			if opts.Logs {
+				logConfig, err = taskdef.CloudWatchLogs(container)
+				utils.CheckErr(err)
+			}
+
+			input := deploy.RunTaskInput(opts.ClusterInput, service, deploy.TaskOverrides{
+				Container:   containerName,
+				Command:     args,
+				Environment: environment,
+			})
+			taskARN, err := opts.client.RunTask(input)
+			utils.CheckErr(err)
+
+			taskID, _ := utils.Last(strings.Split(taskARN, "/"))
+			fmt.Printf("Started task %s of %s\n", taskID, taskdef.Name(definition))
+
+			if !opts.Wait && !opts.Logs {
+				return
+			}
+
+			if opts.Logs {
+				go tailLogs(logConfig, logConfig.TaskStreamPrefix(containerName, taskID))
+			}
+
+			waiter := &deploy.TaskWaiter{
+				Client:   opts.client,
+				Cluster:  opts.ClusterInput,
+				Interval: opts.Interval,
+				Timeout:  opts.Timeout,
+				Out:      os.Stdout,
+			}
+			task, err := waiter.Wait(taskARN, deploy.IsStopped)
+			utils.CheckErr(err)
+
+			if opts.Logs {
+				time.Sleep(logsDrainTime)
+			}
+
+			fmt.Printf("Task %s stopped: %s\n", taskID, deploy.StopDescription(task))
+			code, ok := deploy.ExitCode(task, containerName)
+			if !ok {
+				utils.CheckErr(fmt.Errorf("container %s has no exit code", containerName))
+			}
+			os.Exit(int(code))
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().StringVarP(&opts.ContainerInput, "container", "r", "", "The container to override")
+	cmd.Flags().StringArrayVarP(&opts.Environment, "env", "e", nil, "Set an environment variable as NAME=VALUE, can be repeated")
+	cmd.Flags().BoolVarP(&opts.Wait, "wait", "w", false, "Wait for the task to stop and exit with its exit code")
+	cmd.Flags().BoolVar(&opts.Logs, "logs", false, "Tail the container's logs while waiting, implies --wait")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 30*time.Minute, "How long to wait for the task to stop")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 5*time.Second, "How often to poll the task")
+
+	return cmd
+}
+
+// tailLogs prints the task's logs until it stops. Failing to tail only warns,
+// waiting for the task decides the exit code.
+func tailLogs(logConfig taskdef.LogConfig, prefix string) {
+	// The task has just started so there are no older events. Its stream is
+	// only created once the container starts so a prefix is used,
+	// FilterLogEvents fails for stream names that don't exist.
+	query := client.LogQuery{GroupName: logConfig.GroupName, StreamPrefix: prefix, StartTime: time.Now()}
+	err := logs.NewTailer(opts.client, false, os.Stderr).TailLogs(query, func(e client.LogEvent) {
+		fmt.Printf("[%s] %s\n", e.Timestamp, e.Message)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: stopped tailing", err)
+	}
+}
+
+// parseEnvironment parses the NAME=VALUE environment flags.
+func parseEnvironment(env []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, e := range env {
+		name, value, ok := strings.Cut(e, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid environment variable '%s', use NAME=VALUE", e)
+		}
+		m[name] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,128 @@
+package scale
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/utils"
+)
+
+type scaleOptions struct {
+	ClusterInput string
+	ServiceInput string
+	Count        int32
+	ToZero       bool
+	Restore      bool
+	NoWait       bool
+	Timeout      time.Duration
+	Interval     time.Duration
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var opts = &scaleOptions{}
+
+func NewCmdScale(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Change the desired count of a service",
+		Long: `Change the desired count of a service and wait until the running count matches.
+
+--to-zero remembers the current desired count so --restore can scale the
+service back up later. Services tagged as production (env, environment, or
+stage set to prod or production) need to be confirmed. A warning is shown when
+the Application Auto Scaling bounds of the service would override the count.`,
+		Example: `  going scale -c main -s api --count 3
+  going scale -c main -s worker --to-zero
+  going scale -c main -s worker --restore`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			s := f.Settings()
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = s.Cluster
+			}
+			if opts.ServiceInput == "" {
+				opts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
+
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
+
+			state, err := deploy.ReadScaleState(deploy.ScaleStateFilename())
+			utils.CheckErr(err)
+			key := deploy.ScaleStateKey(f.ProfileName, f.Config().Region, opts.ClusterInput, opts.ServiceInput)
+
+			count := opts.Count
+			if opts.ToZero {
+				count = 0
+			}
+			if opts.Restore {
+				previous, ok := state.Counts[key]
+				if !ok {
+					utils.CheckErr(fmt.Errorf("no previous count saved for service '%s', it has to be scaled with --to-zero first",
+						opts.ServiceInput))
+				}
+				count = previous
+			}
+
+			scaler := &deploy.Scaler{
+				Client:   opts.client,
+				Cluster:  opts.ClusterInput,
+				Service:  opts.ServiceInput,
+				Interval: opts.Interval,
+				Timeout:  opts.Timeout,
+				Out:      os.Stdout,
+				Confirm:  f.Prompt.YesNoPrompt,
+			}
+			previous, err := scaler.Scale(count)
+			if errors.Is(err, deploy.ErrCancelled) {
+				os.Exit(0)
+			}
+			utils.CheckErr(err)
+
+			if opts.ToZero && previous > 0 {
+				state.Counts[key] = previous
+				utils.CheckErr(state.Write())
+			}
+			if opts.Restore {
+				delete(state.Counts, key)
+				utils.CheckErr(state.Write())
+			}
+
+			if !opts.NoWait {
+				utils.CheckErr(scaler.Wait(count))
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().Int32Var(&opts.Count, "count", 0, "The desired count of the service")
+	cmd.Flags().BoolVar(&opts.ToZero, "to-zero", false, "Scale the service to zero, remembering the current count")
+	cmd.Flags().BoolVar(&opts.Restore, "restore", false, "Scale the service back to the count before --to-zero")
+	cmd.Flags().BoolVar(&opts.NoWait, "no-wait", false, "Don't wait for the running count to match")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "How long to wait for the running count to match")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 5*time.Second, "How often to poll the service")
+	cmd.MarkFlagsMutuallyExclusive("count", "to-zero", "restore")
+	cmd.MarkFlagsOneRequired("count", "to-zero", "restore")
+
+	return cmd
+}
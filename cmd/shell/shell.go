@@ -1,26 +1,20 @@
 package shell
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/ecs"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
-	"github.com/aws/session-manager-plugin/src/datachannel"
-	"github.com/aws/session-manager-plugin/src/log"
-	"github.com/aws/session-manager-plugin/src/sessionmanagerplugin/session"
-	"github.com/manifoldco/promptui"
-
-	// import for side effect of registering the shell session
-	_ "github.com/aws/session-manager-plugin/src/sessionmanagerplugin/session/shellsession"
-	"github.com/google/uuid"
-	"github.com/lithammer/fuzzysearch/fuzzy"
 	"github.com/spf13/cobra"
 
 	"going/internal"
 	"going/internal/client"
+	"going/internal/deploy"
+	"going/internal/execsession"
 	"going/internal/factory"
+	"going/internal/history"
+	"going/internal/selector"
 	"going/internal/utils"
 )
 
@@ -29,55 +23,65 @@ type shellOptions struct {
 	ClusterInput   string
 	ServiceInput   string
 	ContainerInput string
+	TaskInput      string
+	TaskPolicy     string
+	Last           bool
+	Command        string
 	UseSSM         bool
 
-	target client.Container
-	client *client.AWSClient
+	target   client.Container
+	client   *client.AWSClient
+	selector *selector.Selector
 }
 
-var opts = &shellOptions{}
+const defaultShellCommand = "/bin/bash"
 
-var containerPromptTemplate = &promptui.SelectTemplates{
-	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
-	Active:   fmt.Sprintf("%s {{ .Name | underline }}", promptui.IconSelect),
-	Inactive: "  {{ .Name }}",
-	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .Name | faint }}`, promptui.IconGood),
-	Details: `{{ "Status:" | faint }} {{ .LastStatus }}
-{{ "Health:" | faint }} {{ .Health }}`,
-}
+var opts = &shellOptions{}
 
 func NewCmdShell(f *factory.Factory) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "shell",
+		Use:   "shell [alias]",
 		Short: "Open a shell to a container in ECS",
+		Long: `Open a shell to a container in ECS.
+
+An alias from the going config can be given to select the profile, cluster,
+service, and container. Flags take precedence over the alias.`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{factory.AliasAnnotation: "true"},
 		PreRun: func(cmd *cobra.Command, args []string) {
+			if opts.Last {
+				utils.CheckErr(history.UseLast(f))
+			}
 			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			applySettings(f)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// Must be logged in
 			err := internal.CheckSSOLogin(f)
 			utils.CheckErr(err)
 
-			if opts.ClusterInput == "" {
-				opts.ClusterInput = promptForCluster(f)
-			}
+			opts.ClusterInput, err = opts.selector.Cluster(opts.ClusterInput)
+			utils.CheckErr(err)
 
-			if opts.ServiceInput == "" {
-				opts.ServiceInput = promptForService(f)
-			}
+			opts.ServiceInput, err = opts.selector.Service(opts.ClusterInput, opts.ServiceInput)
+			utils.CheckErr(err)
 
 			taskARN := getTaskArn(f)
-			opts.target = getContainerDetails(f, taskARN)
+			opts.target, err = opts.selector.Container(opts.ClusterInput, taskARN, opts.ContainerInput)
+			utils.CheckErr(err)
 
 			fmt.Printf("cluster: \"%s\" service: \"%s\" container: \"%s\"\n",
 				opts.target.ClusterName, opts.target.ServiceName, opts.target.Name)
 
 			if !opts.target.ExecuteAgentRunning {
 				fmt.Println("AWS is reporting the \"ExecuteCommandAgent\" is not running, connection will use SSM directly.")
+				fmt.Println("Run `going exec enable` to check and enable ExecuteCommand on the service.")
 				opts.UseSSM = true
 			}
 
-			yes := f.Prompt.YesNoPrompt("Connect to the above container")
+			yes, err := f.Prompt.YesNoPrompt("Connect to the above container")
+			utils.CheckErr(err)
 			if !yes {
 				// just returning seems to sometimes not restore the shells cursor, so exit.
 				os.Exit(0)
@@ -94,141 +98,97 @@ func NewCmdShell(f *factory.Factory) *cobra.Command {
 	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
 	cmd.Flags().StringVarP(&opts.ServiceInput, "service", "s", "", "The service name")
 	cmd.Flags().StringVarP(&opts.ContainerInput, "container", "r", "", "The container name")
+	cmd.Flags().StringVar(&opts.TaskInput, "task", "", "The task ID or ARN")
+	cmd.Flags().StringVar(&opts.TaskPolicy, "task-policy", "",
+		"How to pick a task when multiple are running: newest, random, or healthy")
+	cmd.Flags().BoolVar(&opts.Last, "last", false, "Use the most recently used target")
+	cmd.Flags().StringVar(&opts.Command, "command", "", "The shell command to run in the container (default \"/bin/bash\")")
 	cmd.Flags().BoolVar(&opts.UseSSM, "ssm", false, "Use SSM directly to get a shell")
 
 	return cmd
 }
 
-func promptForCluster(f *factory.Factory) string {
-	c, err := opts.client.ListClusters()
-	utils.CheckErr(err)
-
-	var clusters []string
-	for _, cluster := range c {
-		clusters = append(clusters, cluster.Name)
+// applySettings fills in any flags that weren't given with values from the going config.
+func applySettings(f *factory.Factory) {
+	s := f.Settings()
+	if opts.ClusterInput == "" {
+		opts.ClusterInput = s.Cluster
 	}
-
-	return f.Prompt.Select("Select a cluster", clusters)
-}
-
-func promptForService(f *factory.Factory) string {
-	s, err := opts.client.ListServices(opts.ClusterInput)
-	utils.CheckErr(err)
-
-	var services []string
-	for _, service := range s {
-		services = append(services, service.Name)
+	if opts.ServiceInput == "" {
+		opts.ServiceInput = s.Service
+	}
+	if opts.ContainerInput == "" {
+		opts.ContainerInput = s.Container
+	}
+	if opts.Command == "" {
+		opts.Command = s.Shell
+	}
+	if opts.Command == "" {
+		opts.Command = defaultShellCommand
 	}
-
-	return f.Prompt.Select("Select a service", services)
 }
 
+// getTaskArn returns the task to connect to. If no tasks are running it offers
+// to scale the service to one task and waits for it to start.
 func getTaskArn(f *factory.Factory) string {
-	t, err := opts.client.ListTasks(opts.ClusterInput, opts.ServiceInput)
-	utils.CheckErr(err)
-
-	switch len(t) {
-	case 0:
-		yes := f.Prompt.YesNoPrompt("No tasks running. Start one")
-		if yes {
-			err = opts.client.UpdateService(&ecs.UpdateServiceInput{
-				Cluster:      aws.String(opts.ClusterInput),
-				Service:      aws.String(opts.ServiceInput),
-				DesiredCount: aws.Int32(1),
-			})
-			utils.CheckErr(err)
-			fmt.Println("Set desired count of service to 1. Could take a few minutes to start.")
-		}
-
-		os.Exit(1)
-		return "" // won't reach
-	case 1:
-		return t[0]
-	default:
-		return f.Prompt.Select("Multiple tasks running, please select one", t)
+	taskARN, err := opts.selector.Task(opts.ClusterInput, opts.ServiceInput, opts.TaskInput, opts.TaskPolicy)
+	if !errors.Is(err, selector.ErrNoTasks) {
+		utils.CheckErr(err)
+		return taskARN
 	}
-}
 
-func getContainerDetails(f *factory.Factory, taskARN string) client.Container {
-	var details client.Container
-
-	if opts.ContainerInput == "" {
-		containers, err := opts.client.DescribeContainers(opts.ClusterInput, taskARN)
-		utils.CheckErr(err)
-		i := f.Prompt.CustomSelect("Select a container", containers, containerPromptTemplate, containerSearch(containers))
-		details = containers[i]
+	// The scaler asks for confirmation itself for services tagged as production.
+	service, err := opts.client.DescribeServiceWithTags(opts.ClusterInput, opts.ServiceInput)
+	utils.CheckErr(err)
+	if deploy.IsProduction(service.Tags) {
+		fmt.Println("No tasks running.")
 	} else {
-		c, err := opts.client.DescribeContainer(opts.ClusterInput, taskARN, opts.ContainerInput)
+		yes, err := f.Prompt.YesNoPrompt("No tasks running. Scale the service to 1 and wait for it to start")
 		utils.CheckErr(err)
-		details = c
-	}
-
-	opts.ContainerInput = details.Name
-	return details
-}
-
-func containerSearch(containers []client.Container) func(input string, index int) bool {
-	return func(input string, index int) bool {
-		item := containers[index]
-		if fuzzy.MatchFold(input, item.Name) {
-			return true
+		if !yes {
+			os.Exit(1)
 		}
-		return false
 	}
-}
-
-func getBasicShell(f *factory.Factory) {
-	target, err := opts.target.SSMTarget()
-	utils.CheckErr(err)
 
-	ssmClient := ssm.NewFromConfig(f.Config())
-	out, err := ssmClient.StartSession(f.Context, &ssm.StartSessionInput{Target: aws.String(target)})
+	scaler := &deploy.Scaler{
+		Client:   opts.client,
+		Cluster:  opts.ClusterInput,
+		Service:  opts.ServiceInput,
+		Interval: 5 * time.Second,
+		Timeout:  10 * time.Minute,
+		Out:      os.Stdout,
+		Confirm:  f.Prompt.YesNoPrompt,
+	}
+	_, err = scaler.Scale(1)
+	if errors.Is(err, deploy.ErrCancelled) {
+		os.Exit(1)
+	}
 	utils.CheckErr(err)
+	utils.CheckErr(scaler.Wait(1))
 
-	ep, err := ssm.NewDefaultEndpointResolver().ResolveEndpoint(f.Config().Region, ssm.EndpointResolverOptions{})
+	taskARN, err = opts.selector.Task(opts.ClusterInput, opts.ServiceInput, opts.TaskInput, opts.TaskPolicy)
 	utils.CheckErr(err)
+	return taskARN
+}
 
-	ssmSession := session.Session{
-		SessionId:   aws.ToString(out.SessionId),
-		StreamUrl:   aws.ToString(out.StreamUrl),
-		TokenValue:  aws.ToString(out.TokenValue),
-		Endpoint:    ep.URL,
-		ClientId:    uuid.NewString(),
-		TargetId:    target,
-		DataChannel: &datachannel.DataChannel{},
-	}
-
+func getBasicShell(f *factory.Factory) {
 	fmt.Println("Connecting with a basic `sh' shell. After connecting run `/bin/bash' to get a nicer shell.")
 	fmt.Println("Don't forget you will have to call `exit' twice to end the connection if you change to bash.")
 
-	utils.CheckErr(ssmSession.Execute(log.Logger(false, ssmSession.ClientId)))
+	utils.CheckErr(execsession.StartSSM(f, opts.target, recordTarget(f)))
 }
 
 func getShellUsingECS(f *factory.Factory) {
-	target, err := opts.target.SSMTarget()
-	utils.CheckErr(err)
-
-	out, err := opts.client.ExecuteCommand(&ecs.ExecuteCommandInput{
-		Cluster:     aws.String(opts.target.ClusterARN),
-		Container:   aws.String(opts.target.Name),
-		Task:        aws.String(opts.target.TaskARN),
-		Command:     aws.String("/bin/bash"),
-		Interactive: true,
-	})
-	utils.CheckErr(err)
-
-	ep, err := ssm.NewDefaultEndpointResolver().ResolveEndpoint(f.Config().Region, ssm.EndpointResolverOptions{})
+	err := execsession.Exec(f, opts.client, opts.target, opts.Command, recordTarget(f))
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Run `going doctor exec` to check why the session couldn't start.")
+	}
 	utils.CheckErr(err)
+}
 
-	ssmSession := session.Session{
-		SessionId:   aws.ToString(out.Session.SessionId),
-		StreamUrl:   aws.ToString(out.Session.StreamUrl),
-		TokenValue:  aws.ToString(out.Session.TokenValue),
-		Endpoint:    ep.URL,
-		ClientId:    uuid.NewString(),
-		TargetId:    target,
-		DataChannel: &datachannel.DataChannel{},
+// recordTarget returns a hook recording the target once the session has started.
+func recordTarget(f *factory.Factory) func() {
+	return func() {
+		history.RecordTarget(f, "shell", opts.target)
 	}
-
-	utils.CheckErr(ssmSession.Execute(log.Logger(false, ssmSession.ClientId)))
 }
@@ -0,0 +1,217 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/status"
+	"going/internal/utils"
+)
+
+type statusOptions struct {
+	ClusterInput string
+	AllClusters  bool
+	Once         bool
+	Interval     time.Duration
+	Events       int
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+// clearScreen moves the cursor to the top left and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+var opts = &statusOptions{}
+
+// The styles are only applied on a terminal, not when --once is piped.
+var (
+	green  = utils.Styled(promptui.Styler(promptui.FGGreen))
+	yellow = utils.Styled(promptui.Styler(promptui.FGYellow))
+	red    = utils.Styled(promptui.Styler(promptui.FGRed))
+	faint  = utils.Styled(promptui.Styler(promptui.FGFaint))
+	bold   = utils.Styled(promptui.Styler(promptui.FGBold))
+)
+
+func NewCmdStatus(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of the services in a cluster",
+		Long: `Show the status of the services in a cluster.
+
+For each service the running, pending, and desired task counts, the rollout
+state of the primary deployment, the task definition, and the health and image
+of each container are shown, followed by the most recent events of all the
+services, newest first. The status is refreshed like watch until ctrl+c is
+pressed, or printed once with --once.`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.client = client.New(f.Context, f.Config())
+			opts.selector = selector.New(f, opts.client)
+			if opts.ClusterInput == "" {
+				opts.ClusterInput = f.Settings().Cluster
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			var clusters []string
+			if opts.AllClusters {
+				c, err := opts.client.ListClusters()
+				utils.CheckErr(err)
+				for _, cluster := range c {
+					clusters = append(clusters, cluster.Name)
+				}
+			} else {
+				cluster, err := opts.selector.Cluster(opts.ClusterInput)
+				utils.CheckErr(err)
+				clusters = append(clusters, cluster)
+			}
+
+			// There is no screen to refresh without a terminal.
+			once := opts.Once || f.NonInteractive
+			for {
+				out := strings.Builder{}
+				for _, cluster := range clusters {
+					s, err := clusterStatus(cluster)
+					utils.CheckErr(err)
+					out.WriteString(s)
+				}
+
+				if once {
+					fmt.Print(out.String())
+					return
+				}
+
+				fmt.Print(clearScreen)
+				fmt.Printf("%s\n\n", faint(fmt.Sprintf("Every %s, updated %s", opts.Interval,
+					time.Now().Format(time.TimeOnly))))
+				fmt.Print(out.String())
+				time.Sleep(opts.Interval)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().BoolVarP(&opts.AllClusters, "all", "a", false, "Show the status of all clusters")
+	cmd.Flags().BoolVar(&opts.Once, "once", false, "Print the status once instead of refreshing")
+	cmd.Flags().DurationVarP(&opts.Interval, "interval", "n", 5*time.Second, "How often to refresh the status")
+	cmd.Flags().IntVar(&opts.Events, "events", 3, "Number of recent service events to show per service")
+
+	return cmd
+}
+
+// clusterStatus renders the status of all the services in the cluster.
+func clusterStatus(cluster string) (string, error) {
+	s, err := opts.client.ListServices(cluster)
+	if err != nil {
+		return "", err
+	}
+
+	var names []string
+	for _, service := range s {
+		names = append(names, service.Name)
+	}
+
+	services, err := opts.client.DescribeServices(cluster, names...)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return aws.ToString(services[i].ServiceName) < aws.ToString(services[j].ServiceName)
+	})
+
+	// Listing every task in the cluster at once takes far fewer calls than listing them per service.
+	arns, err := opts.client.ListTasks(cluster, "")
+	if err != nil {
+		return "", err
+	}
+	tasks, err := opts.client.DescribeTasks(cluster, arns...)
+	if err != nil {
+		return "", err
+	}
+
+	tasksByService := status.TasksByService(tasks)
+	var rows [][]string
+	for _, service := range services {
+		rows = append(rows, status.ServiceRows(service, tasksByService[aws.ToString(service.ServiceName)])...)
+	}
+	events := status.RecentEvents(services, opts.Events)
+
+	out := strings.Builder{}
+	out.WriteString(bold(fmt.Sprintf("Cluster: %s", cluster)) + "\n\n")
+	if len(rows) == 0 {
+		out.WriteString("No services\n\n")
+		return out.String(), nil
+	}
+
+	out.WriteString(renderTable(rows))
+	if len(events) > 0 {
+		out.WriteString("\nRecent events:\n")
+		for _, line := range status.EventLines(events, faint) {
+			out.WriteString("  " + line + "\n")
+		}
+	}
+	out.WriteString("\n")
+
+	return out.String(), nil
+}
+
+// renderTable aligns the rows under the headers, coloring the states.
+func renderTable(rows [][]string) string {
+	widths := make([]int, len(status.Headers))
+	for _, row := range append([][]string{status.Headers}, rows...) {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	out := strings.Builder{}
+	for i, h := range status.Headers {
+		out.WriteString(pad(h, widths[i]))
+	}
+	out.WriteString("\n")
+
+	for _, row := range rows {
+		for i, cell := range row {
+			out.WriteString(colorize(status.Headers[i], cell, pad(cell, widths[i])))
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-len(s)+3)
+}
+
+func colorize(header string, value string, padded string) string {
+	switch {
+	case value == "":
+		return padded
+	case header == "ROLLOUT" && value == string(types.DeploymentRolloutStateCompleted):
+		return green(padded)
+	case header == "ROLLOUT" && value == string(types.DeploymentRolloutStateFailed):
+		return red(padded)
+	case header == "ROLLOUT":
+		return yellow(padded)
+	case header == "HEALTH" && strings.Contains(value, string(types.HealthStatusUnhealthy)):
+		return red(padded)
+	case header == "HEALTH" && strings.Contains(value, string(types.HealthStatusHealthy)):
+		return green(padded)
+	}
+	return padded
+}
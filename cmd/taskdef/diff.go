@@ -0,0 +1,150 @@
+package taskdef
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+
+	"going/internal"
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/selector"
+	"going/internal/taskdef"
+	"going/internal/utils"
+)
+
+type diffOptions struct {
+	ClusterInput string
+	ServiceInput string
+	NoColor      bool
+
+	client   *client.AWSClient
+	selector *selector.Selector
+}
+
+var diffOpts = &diffOptions{}
+
+var (
+	green  = promptui.Styler(promptui.FGGreen)
+	yellow = promptui.Styler(promptui.FGYellow)
+	red    = promptui.Styler(promptui.FGRed)
+	bold   = promptui.Styler(promptui.FGBold)
+)
+
+func NewCmdDiff(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [FROM] [TO]",
+		Short: "Show the differences between two task definition revisions",
+		Long: `Show the differences between two task definition revisions.
+
+The revisions can be given as family:revision, a family for its latest
+revision, or an ARN. Without arguments the revision the service is running is
+compared to the latest registered revision of its family. With one argument the
+running revision is compared to it.
+
+The images, environment variables, secret references, CPU and memory, log
+configuration, port mappings, and health checks of each container are compared.`,
+		Args: cobra.MaximumNArgs(2),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			diffOpts.client = client.New(f.Context, f.Config())
+			diffOpts.selector = selector.New(f, diffOpts.client)
+			s := f.Settings()
+			if diffOpts.ClusterInput == "" {
+				diffOpts.ClusterInput = s.Cluster
+			}
+			if diffOpts.ServiceInput == "" {
+				diffOpts.ServiceInput = s.Service
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := internal.CheckSSOLogin(f)
+			utils.CheckErr(err)
+
+			var from, to *types.TaskDefinition
+			if len(args) == 2 {
+				from, err = diffOpts.client.DescribeTaskDefinition(args[0])
+				utils.CheckErr(err)
+				to, err = diffOpts.client.DescribeTaskDefinition(args[1])
+				utils.CheckErr(err)
+			} else {
+				from = runningTaskDefinition()
+				if len(args) == 1 {
+					to, err = diffOpts.client.DescribeTaskDefinition(args[0])
+				} else {
+					// Describing the family returns its latest active revision.
+					to, err = diffOpts.client.DescribeTaskDefinition(aws.ToString(from.Family))
+				}
+				utils.CheckErr(err)
+			}
+
+			printDiff(from, to, !diffOpts.NoColor && utils.StdoutIsTerminal())
+		},
+	}
+
+	cmd.Flags().StringVarP(&diffOpts.ClusterInput, "cluster", "c", "", "The cluster name")
+	cmd.Flags().StringVarP(&diffOpts.ServiceInput, "service", "s", "", "The service name")
+	cmd.Flags().BoolVar(&diffOpts.NoColor, "no-color", false, "Don't color the differences")
+
+	return cmd
+}
+
+// runningTaskDefinition returns the task definition of the selected service.
+func runningTaskDefinition() *types.TaskDefinition {
+	var err error
+	diffOpts.ClusterInput, err = diffOpts.selector.Cluster(diffOpts.ClusterInput)
+	utils.CheckErr(err)
+
+	diffOpts.ServiceInput, err = diffOpts.selector.Service(diffOpts.ClusterInput, diffOpts.ServiceInput)
+	utils.CheckErr(err)
+
+	service, err := diffOpts.client.DescribeService(diffOpts.ClusterInput, diffOpts.ServiceInput)
+	utils.CheckErr(err)
+
+	definition, err := diffOpts.client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+	utils.CheckErr(err)
+	return definition
+}
+
+// printDiff prints the changes grouped by container, task level changes first.
+func printDiff(from *types.TaskDefinition, to *types.TaskDefinition, color bool) {
+	style := func(s func(interface{}) string, v string) string {
+		if color {
+			return s(v)
+		}
+		return v
+	}
+
+	fmt.Println(style(bold, fmt.Sprintf("%s -> %s", taskdef.Name(from), taskdef.Name(to))))
+
+	changes := taskdef.Diff(from, to)
+	if len(changes) == 0 {
+		fmt.Println("No differences.")
+		return
+	}
+
+	group := ""
+	for i, c := range changes {
+		name := c.Container
+		if name == "" {
+			name = "task"
+		}
+		if i == 0 || name != group {
+			group = name
+			fmt.Println(style(bold, group))
+		}
+
+		line := fmt.Sprintf("  %s %s: %s", c.Symbol(), c.Field, c.Value())
+		switch c.Symbol() {
+		case "+":
+			line = style(green, line)
+		case "-":
+			line = style(red, line)
+		default:
+			line = style(yellow, line)
+		}
+		fmt.Println(line)
+	}
+}
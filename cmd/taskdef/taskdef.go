@@ -0,0 +1,18 @@
+package taskdef
+
+import (
+	"github.com/spf13/cobra"
+
+	"going/internal/factory"
+)
+
+func NewCmdTaskdef(f *factory.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taskdef",
+		Short: "Work with ECS task definitions",
+	}
+
+	cmd.AddCommand(NewCmdDiff(f))
+
+	return cmd
+}
@@ -2,16 +2,28 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/applicationautoscaling"
+	aastypes "github.com/aws/aws-sdk-go-v2/service/applicationautoscaling/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"going/internal/utils"
 )
@@ -20,55 +32,126 @@ const (
 	// The format for setting the Target of an ECS container in the SSM Session.
 	ecsTargetFormat    = "ecs:%s_%s_%s"
 	groupServicePrefix = "service:"
+	// describeTasksLimit the max number of tasks DescribeTasks accepts per call.
+	describeTasksLimit = 100
+	// describeServicesLimit the max number of services DescribeServices accepts per call.
+	describeServicesLimit = 10
 )
 
+// ErrTaskNotFound is returned when DescribeTasks doesn't return the task, e.g.
+// right after RunTask before ECS knows about it.
+var ErrTaskNotFound = errors.New("no tasks found")
+
 type AWSClient struct {
-	ctx       context.Context
-	ecsClient *ecs.Client
-	logClient *cloudwatchlogs.Client
+	ctx           context.Context
+	ecsClient     *ecs.Client
+	logClient     *cloudwatchlogs.Client
+	scalingClient *applicationautoscaling.Client
+	s3Client      *s3.Client
+	ssmClient     *ssm.Client
+	secretsClient *secretsmanager.Client
+	iamClient     *iam.Client
+	ec2Client     *ec2.Client
+	stsClient     *sts.Client
 }
 
 type Cluster struct {
-	Name string
-	ARN  string
+	Name string `json:"name" yaml:"name"`
+	ARN  string `json:"arn" yaml:"arn"`
 }
 
 type Service struct {
-	Name string
-	ARN  string
+	Name string `json:"name" yaml:"name"`
+	ARN  string `json:"arn" yaml:"arn"`
 }
 
 type Task struct {
-	ARN           string
-	DefinitionARN string
-	ClusterARN    string
-	ClusterName   string
-	ServiceName   string
-	Containers    []Container
+	ARN           string      `json:"arn" yaml:"arn"`
+	DefinitionARN string      `json:"definitionArn" yaml:"definitionArn"`
+	ClusterARN    string      `json:"clusterArn" yaml:"clusterArn"`
+	ClusterName   string      `json:"clusterName" yaml:"clusterName"`
+	ServiceName   string      `json:"serviceName" yaml:"serviceName"`
+	Containers    []Container `json:"containers" yaml:"containers"`
+
+	LastStatus    string    `json:"lastStatus" yaml:"lastStatus"`
+	DesiredStatus string    `json:"desiredStatus" yaml:"desiredStatus"`
+	Health        string    `json:"health" yaml:"health"`
+	CreatedAt     time.Time `json:"createdAt" yaml:"createdAt"`
+	StartedAt     time.Time `json:"startedAt" yaml:"startedAt"`
+	StoppedAt     time.Time `json:"stoppedAt" yaml:"stoppedAt"`
+	StopCode      string    `json:"stopCode" yaml:"stopCode"`
+	StoppedReason string    `json:"stoppedReason" yaml:"stoppedReason"`
+
+	ExecuteCommandEnabled bool   `json:"executeCommandEnabled" yaml:"executeCommandEnabled"`
+	SubnetID              string `json:"subnetId" yaml:"subnetId"`
 }
 
 type Container struct {
-	Name              string
-	ARN               string
-	ClusterARN        string
-	ClusterName       string
-	ServiceName       string
-	TaskARN           string
-	TaskDefinitionARN string
-	RuntimeID         string
+	Name              string `json:"name" yaml:"name"`
+	ARN               string `json:"arn" yaml:"arn"`
+	ClusterARN        string `json:"clusterArn" yaml:"clusterArn"`
+	ClusterName       string `json:"clusterName" yaml:"clusterName"`
+	ServiceName       string `json:"serviceName" yaml:"serviceName"`
+	TaskARN           string `json:"taskArn" yaml:"taskArn"`
+	TaskDefinitionARN string `json:"taskDefinitionArn" yaml:"taskDefinitionArn"`
+	RuntimeID         string `json:"runtimeId" yaml:"runtimeId"`
+
+	ExecuteAgentRunning bool `json:"executeAgentRunning" yaml:"executeAgentRunning"`
+
+	Health      string `json:"health" yaml:"health"`
+	LastStatus  string `json:"lastStatus" yaml:"lastStatus"`
+	Image       string `json:"image" yaml:"image"`
+	ImageDigest string `json:"imageDigest" yaml:"imageDigest"`
+	CPU         string `json:"cpu" yaml:"cpu"`
+	Memory      string `json:"memory" yaml:"memory"`
+	ExitCode    *int32 `json:"exitCode" yaml:"exitCode"`
+	Reason      string `json:"reason" yaml:"reason"`
+}
+
+// ScalingBounds the min and max capacity Application Auto Scaling keeps a service within.
+type ScalingBounds struct {
+	Min int32
+	Max int32
+}
+
+// LogQuery selects the log events to read from a log group. The stream names
+// take precedence over the stream prefix. EndTime is only used when reading a
+// window of events, tailing follows new events. FilterPattern is a CloudWatch
+// Logs filter pattern applied by the service.
+type LogQuery struct {
+	GroupName     string
+	StreamPrefix  string
+	StreamNames   []string
+	StartTime     time.Time
+	EndTime       time.Time
+	FilterPattern string
+}
 
-	ExecuteAgentRunning bool
+// InsightsQuery is a CloudWatch Logs Insights query over log groups.
+type InsightsQuery struct {
+	GroupNames []string
+	Query      string
+	StartTime  time.Time
+	EndTime    time.Time
+	// Limit is the most rows returned, the query's own limit or the
+	// service's default of 1000 when zero.
+	Limit int32
+}
 
-	Health      string
-	LastStatus  string
-	Image       string
-	ImageDigest string
-	CPU         string
-	Memory      string
+// InsightsResults are the results of a Logs Insights query so far. The
+// fields are in the order they first appear in the rows.
+type InsightsResults struct {
+	Status         string
+	Fields         []string
+	Rows           []map[string]string
+	RecordsMatched float64
+	RecordsScanned float64
+	BytesScanned   float64
 }
 
 type LogEvent struct {
 	ID            string
+	GroupName     string
 	StreamName    string
 	Timestamp     time.Time
 	IngestionTime time.Time
@@ -77,9 +160,16 @@ type LogEvent struct {
 
 func New(ctx context.Context, cfg aws.Config) *AWSClient {
 	return &AWSClient{
-		ctx:       ctx,
-		ecsClient: ecs.NewFromConfig(cfg),
-		logClient: cloudwatchlogs.NewFromConfig(cfg),
+		ctx:           ctx,
+		ecsClient:     ecs.NewFromConfig(cfg),
+		logClient:     cloudwatchlogs.NewFromConfig(cfg),
+		scalingClient: applicationautoscaling.NewFromConfig(cfg),
+		s3Client:      s3.NewFromConfig(cfg),
+		ssmClient:     ssm.NewFromConfig(cfg),
+		secretsClient: secretsmanager.NewFromConfig(cfg),
+		iamClient:     iam.NewFromConfig(cfg),
+		ec2Client:     ec2.NewFromConfig(cfg),
+		stsClient:     sts.NewFromConfig(cfg),
 	}
 }
 
@@ -128,11 +218,28 @@ func (c *AWSClient) ListServices(cluster string) ([]Service, error) {
 }
 
 // ListTasks returns all task ARNs for the cluster with the given service name.
+// If the service name is blank then all tasks in the cluster are returned.
 func (c *AWSClient) ListTasks(cluster string, service string) ([]string, error) {
-	pager := ecs.NewListTasksPaginator(c.ecsClient, &ecs.ListTasksInput{
-		Cluster:     aws.String(cluster),
-		ServiceName: aws.String(service),
-	})
+	return c.listTasks(cluster, service, types.DesiredStatusRunning)
+}
+
+// ListStoppedTasks returns the ARNs of the recently stopped tasks for the
+// cluster with the given service name. ECS only keeps stopped tasks for about
+// an hour.
+func (c *AWSClient) ListStoppedTasks(cluster string, service string) ([]string, error) {
+	return c.listTasks(cluster, service, types.DesiredStatusStopped)
+}
+
+func (c *AWSClient) listTasks(cluster string, service string, status types.DesiredStatus) ([]string, error) {
+	input := &ecs.ListTasksInput{
+		Cluster:       aws.String(cluster),
+		DesiredStatus: status,
+	}
+	if service != "" {
+		input.ServiceName = aws.String(service)
+	}
+
+	pager := ecs.NewListTasksPaginator(c.ecsClient, input)
 
 	var tasks []string
 	for pager.HasMorePages() {
@@ -151,16 +258,25 @@ func (c *AWSClient) ListTasks(cluster string, service string) ([]string, error)
 
 // DescribeTasks returns all tasks in the cluster for the given task ARNs.
 func (c *AWSClient) DescribeTasks(cluster string, taskARNs ...string) ([]Task, error) {
-	result, err := c.ecsClient.DescribeTasks(c.ctx, &ecs.DescribeTasksInput{
-		Cluster: aws.String(cluster),
-		Tasks:   taskARNs,
-	})
-	if err != nil {
-		return nil, err
+	var described []types.Task
+	for start := 0; start < len(taskARNs); start += describeTasksLimit {
+		end := start + describeTasksLimit
+		if end > len(taskARNs) {
+			end = len(taskARNs)
+		}
+
+		result, err := c.ecsClient.DescribeTasks(c.ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   taskARNs[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		described = append(described, result.Tasks...)
 	}
 
 	var tasks []Task
-	for _, task := range result.Tasks {
+	for _, task := range described {
 		clusterName, _ := utils.Last(strings.Split(aws.ToString(task.ClusterArn), "/"))
 		// The Group appears to be the name of the service prefixed with "service:".
 		serviceName := strings.TrimPrefix(aws.ToString(task.Group), groupServicePrefix)
@@ -171,6 +287,17 @@ func (c *AWSClient) DescribeTasks(cluster string, taskARNs ...string) ([]Task, e
 			ClusterName:   clusterName,
 			ServiceName:   serviceName,
 			DefinitionARN: aws.ToString(task.TaskDefinitionArn),
+			LastStatus:    aws.ToString(task.LastStatus),
+			DesiredStatus: aws.ToString(task.DesiredStatus),
+			Health:        string(task.HealthStatus),
+			CreatedAt:     aws.ToTime(task.CreatedAt),
+			StartedAt:     aws.ToTime(task.StartedAt),
+			StoppedAt:     aws.ToTime(task.StoppedAt),
+			StopCode:      string(task.StopCode),
+			StoppedReason: aws.ToString(task.StoppedReason),
+
+			ExecuteCommandEnabled: task.EnableExecuteCommand,
+			SubnetID:              attachmentDetail(task.Attachments, "subnetId"),
 		}
 
 		for _, container := range task.Containers {
@@ -191,6 +318,8 @@ func (c *AWSClient) DescribeTasks(cluster string, taskARNs ...string) ([]Task, e
 				ImageDigest:         aws.ToString(container.ImageDigest),
 				CPU:                 aws.ToString(container.Cpu),
 				Memory:              aws.ToString(container.Memory),
+				ExitCode:            container.ExitCode,
+				Reason:              aws.ToString(container.Reason),
 			})
 		}
 		tasks = append(tasks, t)
@@ -199,6 +328,98 @@ func (c *AWSClient) DescribeTasks(cluster string, taskARNs ...string) ([]Task, e
 	return tasks, nil
 }
 
+// DescribeServices returns the details of the services in the cluster.
+func (c *AWSClient) DescribeServices(cluster string, services ...string) ([]types.Service, error) {
+	return c.describeServices(cluster, nil, services...)
+}
+
+// describeServices describes the services with the optional fields in include.
+func (c *AWSClient) describeServices(cluster string, include []types.ServiceField, services ...string) ([]types.Service, error) {
+	var described []types.Service
+	for start := 0; start < len(services); start += describeServicesLimit {
+		end := start + describeServicesLimit
+		if end > len(services) {
+			end = len(services)
+		}
+
+		result, err := c.ecsClient.DescribeServices(c.ctx, &ecs.DescribeServicesInput{
+			Cluster:  aws.String(cluster),
+			Services: services[start:end],
+			Include:  include,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Failures) > 0 {
+			f := result.Failures[0]
+			return nil, fmt.Errorf("failed to describe service %s, %s", aws.ToString(f.Arn), aws.ToString(f.Reason))
+		}
+		described = append(described, result.Services...)
+	}
+
+	return described, nil
+}
+
+// DescribeService returns the details of a single service.
+func (c *AWSClient) DescribeService(cluster string, service string) (types.Service, error) {
+	return c.describeService(cluster, service, nil)
+}
+
+// DescribeServiceWithTags returns the details of the service including its tags,
+// which need the ecs:ListTagsForResource permission.
+func (c *AWSClient) DescribeServiceWithTags(cluster string, service string) (types.Service, error) {
+	return c.describeService(cluster, service, []types.ServiceField{types.ServiceFieldTags})
+}
+
+func (c *AWSClient) describeService(cluster string, service string, include []types.ServiceField) (types.Service, error) {
+	result, err := c.describeServices(cluster, include, service)
+	if err != nil {
+		return types.Service{}, err
+	}
+
+	if len(result) <= 0 {
+		return types.Service{}, fmt.Errorf("no service '%s' found in cluster '%s'", service, cluster)
+	}
+
+	return result[0], nil
+}
+
+// ScalingBounds returns the Application Auto Scaling bounds of the service,
+// or nil if the service isn't registered as a scalable target.
+func (c *AWSClient) ScalingBounds(cluster string, service string) (*ScalingBounds, error) {
+	result, err := c.scalingClient.DescribeScalableTargets(c.ctx, &applicationautoscaling.DescribeScalableTargetsInput{
+		ServiceNamespace:  aastypes.ServiceNamespaceEcs,
+		ResourceIds:       []string{fmt.Sprintf("service/%s/%s", cluster, service)},
+		ScalableDimension: aastypes.ScalableDimensionECSServiceDesiredCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.ScalableTargets) == 0 {
+		return nil, nil
+	}
+
+	target := result.ScalableTargets[0]
+	return &ScalingBounds{Min: aws.ToInt32(target.MinCapacity), Max: aws.ToInt32(target.MaxCapacity)}, nil
+}
+
+// DescribeCluster returns the cluster including its execute command configuration.
+func (c *AWSClient) DescribeCluster(cluster string) (types.Cluster, error) {
+	result, err := c.ecsClient.DescribeClusters(c.ctx, &ecs.DescribeClustersInput{
+		Clusters: []string{cluster},
+		Include:  []types.ClusterField{types.ClusterFieldConfigurations},
+	})
+	if err != nil {
+		return types.Cluster{}, err
+	}
+
+	if len(result.Clusters) == 0 {
+		return types.Cluster{}, fmt.Errorf("cluster %s not found", cluster)
+	}
+	return result.Clusters[0], nil
+}
+
 // DescribeTask returns the first task.
 func (c *AWSClient) DescribeTask(cluster string, taskARN string) (Task, error) {
 	result, err := c.DescribeTasks(cluster, taskARN)
@@ -207,7 +428,7 @@ func (c *AWSClient) DescribeTask(cluster string, taskARN string) (Task, error) {
 	}
 
 	if len(result) <= 0 {
-		return Task{}, fmt.Errorf("no tasks found for cluster %s with task ARN %s", cluster, taskARN)
+		return Task{}, fmt.Errorf("%w for cluster %s with task ARN %s", ErrTaskNotFound, cluster, taskARN)
 	}
 
 	return result[0], nil
@@ -225,6 +446,56 @@ func (c *AWSClient) DescribeTaskDefinition(definitionARN string) (*types.TaskDef
 	return result.TaskDefinition, nil
 }
 
+// RegisterTaskDefinition registers a new task definition revision.
+func (c *AWSClient) RegisterTaskDefinition(params *ecs.RegisterTaskDefinitionInput) (*types.TaskDefinition, error) {
+	result, err := c.ecsClient.RegisterTaskDefinition(c.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.TaskDefinition, nil
+}
+
+// DeregisterTaskDefinition marks the task definition revision as inactive.
+func (c *AWSClient) DeregisterTaskDefinition(definitionARN string) error {
+	_, err := c.ecsClient.DeregisterTaskDefinition(c.ctx, &ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: aws.String(definitionARN),
+	})
+	return err
+}
+
+// ListTaskDefinitions returns up to max of the newest active revision ARNs of the task definition family.
+// ECS only filters by family prefix, so revisions of other families starting with the same name are
+// left out here.
+func (c *AWSClient) ListTaskDefinitions(family string, max int) ([]string, error) {
+	pager := ecs.NewListTaskDefinitionsPaginator(c.ecsClient, &ecs.ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       types.TaskDefinitionStatusActive,
+		Sort:         types.SortOrderDesc,
+	})
+
+	var definitions []string
+	for pager.HasMorePages() && len(definitions) < max {
+		result, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range result.TaskDefinitionArns {
+			// Skip other families starting with the same name, e.g. api-worker for api.
+			name, _ := utils.Last(strings.Split(a, "/"))
+			if f, _, _ := strings.Cut(name, ":"); f != family {
+				continue
+			}
+			if len(definitions) < max {
+				definitions = append(definitions, a)
+			}
+		}
+	}
+
+	return definitions, nil
+}
+
 // DescribeContainers get details about the containers for the given cluster and task ARN.
 func (c *AWSClient) DescribeContainers(cluster string, taskARN string) ([]Container, error) {
 	result, err := c.DescribeTask(cluster, taskARN)
@@ -260,6 +531,37 @@ func (c *AWSClient) UpdateService(params *ecs.UpdateServiceInput) error {
 	return nil
 }
 
+// RunTask starts a task and returns its ARN.
+func (c *AWSClient) RunTask(params *ecs.RunTaskInput) (string, error) {
+	result, err := c.ecsClient.RunTask(c.ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.Failures) > 0 {
+		failure := result.Failures[0]
+		return "", fmt.Errorf("failed to run task, %s: %s", aws.ToString(failure.Reason), aws.ToString(failure.Detail))
+	}
+	if len(result.Tasks) == 0 {
+		return "", fmt.Errorf("no task was started")
+	}
+
+	return aws.ToString(result.Tasks[0].TaskArn), nil
+}
+
+// StopTask stops the task, the reason is shown in the task's stopped reason.
+func (c *AWSClient) StopTask(cluster string, taskARN string, reason string) error {
+	_, err := c.ecsClient.StopTask(c.ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(taskARN),
+		Reason:  aws.String(reason),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // ExecuteCommand calls the ecs.Client.ExecuteCommand method.
 func (c *AWSClient) ExecuteCommand(params *ecs.ExecuteCommandInput) (*ecs.ExecuteCommandOutput, error) {
 	output, err := c.ecsClient.ExecuteCommand(c.ctx, params)
@@ -269,19 +571,177 @@ func (c *AWSClient) ExecuteCommand(params *ecs.ExecuteCommandInput) (*ecs.Execut
 	return output, nil
 }
 
-// TailLogs tails logs for a specified log group, starting from the specified
-// startTime, and invokes the eventHandler function for each log event received.
-// It continuously paginates
-func (c *AWSClient) TailLogs(groupName string, streamPrefix string, startTime time.Time, eventHandler func(event LogEvent)) error {
-	// Not really necessary but it makes the console look nice when exiting.
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
-	go func() {
-		<-sigChan
-		fmt.Println("\nCaught ctrl+c, quit!")
-		os.Exit(0)
-	}()
+// GetObject returns the contents of the S3 object with the ARN
+// arn:partition:s3:::bucket/key.
+func (c *AWSClient) GetObject(objectARN string) ([]byte, error) {
+	bucket, key, err := parseObjectARN(objectARN)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.s3Client.GetObject(c.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	return io.ReadAll(result.Body)
+}
+
+// parseObjectARN returns the bucket and key of an S3 object ARN in any partition.
+func parseObjectARN(objectARN string) (string, string, error) {
+	parsed, err := arn.Parse(objectARN)
+	if err != nil || parsed.Service != "s3" {
+		return "", "", fmt.Errorf("invalid S3 object ARN '%s'", objectARN)
+	}
+	bucket, key, ok := strings.Cut(parsed.Resource, "/")
+	if !ok || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid S3 object ARN '%s'", objectARN)
+	}
+	return bucket, key, nil
+}
+
+// GetParameter returns the decrypted value of the SSM parameter with the name or ARN.
+func (c *AWSClient) GetParameter(name string) (string, error) {
+	result, err := c.ssmClient.GetParameter(c.ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(result.Parameter.Value), nil
+}
+
+// GetSecretValue returns the string value of the secret. The version stage
+// and ID are optional.
+func (c *AWSClient) GetSecretValue(secretID string, versionStage string, versionID string) (string, error) {
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)}
+	if versionStage != "" {
+		input.VersionStage = aws.String(versionStage)
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	result, err := c.secretsClient.GetSecretValue(c.ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(result.SecretString), nil
+}
+
+// SimulatePrincipalPolicy evaluates the policies of the IAM user or role for
+// the actions, returning the decision for each action. Without resources the
+// actions are evaluated for all resources. An action is only allowed when it
+// is allowed on every resource, otherwise the first denial is returned.
+func (c *AWSClient) SimulatePrincipalPolicy(principalARN string, resourceARNs []string, actions ...string) (map[string]string, error) {
+	pager := iam.NewSimulatePrincipalPolicyPaginator(c.iamClient, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     actions,
+		ResourceArns:    resourceARNs,
+	})
+
+	decisions := map[string]string{}
+	for pager.HasMorePages() {
+		result, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range result.EvaluationResults {
+			action := aws.ToString(r.EvalActionName)
+			decisions[action] = denialOf(decisions[action], string(r.EvalDecision))
+			for _, rr := range r.ResourceSpecificResults {
+				decisions[action] = denialOf(decisions[action], string(rr.EvalResourceDecision))
+			}
+		}
+	}
+
+	return decisions, nil
+}
+
+// denialOf returns the current decision unless it is allowed or unset, so a
+// denial for one resource isn't replaced by another resource being allowed.
+func denialOf(current string, decision string) string {
+	if current == "" || current == string(iamtypes.PolicyEvaluationDecisionTypeAllowed) {
+		return decision
+	}
+	return current
+}
+
+// CallerARN returns the ARN of the IAM user or role of the credentials. For an
+// assumed role the role's ARN, including its path, is returned instead of the
+// session's ARN so it can be used for policy simulation.
+func (c *AWSClient) CallerARN() (string, error) {
+	identity, err := c.stsClient.GetCallerIdentity(c.ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+
+	callerARN := aws.ToString(identity.Arn)
+	// arn:aws:sts::account:assumed-role/role-name/session-name
+	_, resource, _ := strings.Cut(callerARN, ":assumed-role/")
+	roleName, _, ok := strings.Cut(resource, "/")
+	if !ok {
+		return callerARN, nil
+	}
+
+	role, err := c.iamClient.GetRole(c.ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(role.Role.Arn), nil
+}
+
+// SubnetVPC returns the ID of the VPC the subnet is in.
+func (c *AWSClient) SubnetVPC(subnetID string) (string, error) {
+	result, err := c.ec2Client.DescribeSubnets(c.ctx, &ec2.DescribeSubnetsInput{SubnetIds: []string{subnetID}})
+	if err != nil {
+		return "", err
+	}
+
+	if len(result.Subnets) == 0 {
+		return "", fmt.Errorf("subnet %s not found", subnetID)
+	}
+	return aws.ToString(result.Subnets[0].VpcId), nil
+}
+
+// VPCEndpoints returns the IDs of the VPC's endpoints for the service name,
+// e.g. com.amazonaws.us-east-1.ssmmessages.
+func (c *AWSClient) VPCEndpoints(vpcID string, serviceName string) ([]string, error) {
+	pager := ec2.NewDescribeVpcEndpointsPaginator(c.ec2Client, &ec2.DescribeVpcEndpointsInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("vpc-id"), Values: []string{vpcID}},
+			{Name: aws.String("service-name"), Values: []string{serviceName}},
+		},
+	})
+
+	var endpoints []string
+	for pager.HasMorePages() {
+		result, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range result.VpcEndpoints {
+			endpoints = append(endpoints, aws.ToString(e.VpcEndpointId))
+		}
+	}
+
+	return endpoints, nil
+}
 
+// PollLogs tails the log events matching the query, starting from the query's
+// StartTime, and invokes the eventHandler function for each log event received.
+// It re-runs FilterLogEvents every few seconds until it fails.
+func (c *AWSClient) PollLogs(query LogQuery, eventHandler func(event LogEvent)) error {
+	startTime := query.StartTime
 	// Set the timestamp to now in case there are no events we don't try to send a negative start time.
 	lastEvent := LogEvent{Timestamp: time.Now(), ID: ""}
 	// This is for tracking events with the exact same timestamps.
@@ -289,53 +749,358 @@ func (c *AWSClient) TailLogs(groupName string, streamPrefix string, startTime ti
 	// message over and over.
 	lastEventIDs := map[string]struct{}{}
 	for {
-		filterInput := &cloudwatchlogs.FilterLogEventsInput{
-			LogGroupName: aws.String(groupName),
-			StartTime:    aws.Int64(startTime.UnixMilli()),
+		err := c.filterLogEvents(query, startTime, time.Time{}, func(currentEvent LogEvent) {
+			if _, ok := lastEventIDs[currentEvent.ID]; ok {
+				return
+			}
+
+			if currentEvent.Timestamp.Equal(lastEvent.Timestamp) {
+				lastEventIDs[currentEvent.ID] = struct{}{}
+			}
+
+			if currentEvent.Timestamp.After(lastEvent.Timestamp) {
+				lastEventIDs = map[string]struct{}{
+					currentEvent.ID: {},
+				}
+			}
+
+			eventHandler(currentEvent)
+
+			lastEvent = currentEvent
+		})
+		if err != nil {
+			return err
+		}
+
+		startTime = lastEvent.Timestamp
+		time.Sleep(3 * time.Second)
+	}
+}
+
+// LiveTailLogs streams the log events matching the query with a CloudWatch
+// Logs Live Tail session. Live Tail only delivers events ingested after the
+// session starts, so the events since the query's StartTime are read with
+// FilterLogEvents first. Sessions end after three hours and are restarted
+// from the last event delivered.
+func (c *AWSClient) LiveTailLogs(query LogQuery, eventHandler func(event LogEvent)) error {
+	groupARN, err := c.LogGroupARN(query.GroupName)
+	if err != nil {
+		return err
+	}
+
+	input := &cloudwatchlogs.StartLiveTailInput{LogGroupIdentifiers: []string{groupARN}}
+	if query.FilterPattern != "" {
+		input.LogEventFilterPattern = aws.String(query.FilterPattern)
+	}
+	// StartLiveTail doesn't accept both.
+	if len(query.StreamNames) > 0 {
+		input.LogStreamNames = query.StreamNames
+	} else if query.StreamPrefix != "" {
+		input.LogStreamNamePrefixes = []string{query.StreamPrefix}
+	}
+
+	recent := &recentEvents{}
+	deliver := func(e LogEvent) {
+		if recent.add(e) {
+			eventHandler(e)
+		}
+	}
+
+	startTime := query.StartTime
+	for {
+		output, err := c.logClient.StartLiveTail(c.ctx, input)
+		if err != nil {
+			return err
 		}
-		if streamPrefix != "" {
-			filterInput.LogStreamNamePrefix = aws.String(streamPrefix)
+		stream := output.GetStream()
+
+		// The first event confirms the session started, anything ingested
+		// after it is delivered by the session.
+		if _, ok := <-stream.Events(); !ok {
+			_ = stream.Close()
+			return stream.Err()
 		}
+		sessionStart := time.Now()
 
-		pager := cloudwatchlogs.NewFilterLogEventsPaginator(c.logClient, filterInput)
-		for pager.HasMorePages() {
-			result, err := pager.NextPage(c.ctx)
+		if startTime.Before(sessionStart) {
+			err = c.filterLogEvents(query, startTime, sessionStart, deliver)
 			if err != nil {
+				_ = stream.Close()
 				return err
 			}
+		}
 
-			for _, event := range result.Events {
-				currentEvent := LogEvent{
-					ID:            aws.ToString(event.EventId),
-					StreamName:    aws.ToString(event.LogStreamName),
-					Timestamp:     time.UnixMilli(aws.ToInt64(event.Timestamp)),
-					IngestionTime: time.UnixMilli(aws.ToInt64(event.IngestionTime)),
-					Message:       aws.ToString(event.Message),
-				}
+		err = readLiveTail(stream, query.GroupName, deliver)
+		var timeout *cwltypes.SessionTimeoutException
+		if err != nil && !errors.As(err, &timeout) {
+			return err
+		}
 
-				if _, ok := lastEventIDs[currentEvent.ID]; ok {
-					continue
-				}
+		startTime = sessionStart
+		if last := recent.last(); last.After(startTime) {
+			startTime = last
+		}
+	}
+}
 
-				if currentEvent.Timestamp.Equal(lastEvent.Timestamp) {
-					lastEventIDs[currentEvent.ID] = struct{}{}
-				}
+// ReadLogs invokes the eventHandler function for each log event matching the
+// query between its StartTime and EndTime, up to the latest event if EndTime
+// is zero, then returns.
+func (c *AWSClient) ReadLogs(query LogQuery, eventHandler func(event LogEvent)) error {
+	return c.filterLogEvents(query, query.StartTime, query.EndTime, eventHandler)
+}
 
-				if currentEvent.Timestamp.After(lastEvent.Timestamp) {
-					lastEventIDs = map[string]struct{}{
-						currentEvent.ID: {},
-					}
-				}
+// StartQuery starts a Logs Insights query and returns its ID.
+func (c *AWSClient) StartQuery(query InsightsQuery) (string, error) {
+	input := &cloudwatchlogs.StartQueryInput{
+		LogGroupNames: query.GroupNames,
+		QueryString:   aws.String(query.Query),
+		StartTime:     aws.Int64(query.StartTime.Unix()),
+		EndTime:       aws.Int64(query.EndTime.Unix()),
+	}
+	if query.Limit > 0 {
+		input.Limit = aws.Int32(query.Limit)
+	}
+
+	result, err := c.logClient.StartQuery(c.ctx, input)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(result.QueryId), nil
+}
 
-				eventHandler(currentEvent)
+// GetQueryResults returns the status and results of a Logs Insights query.
+// The @ptr field used to look up the full event is left out.
+func (c *AWSClient) GetQueryResults(queryID string) (InsightsResults, error) {
+	result, err := c.logClient.GetQueryResults(c.ctx, &cloudwatchlogs.GetQueryResultsInput{
+		QueryId: aws.String(queryID),
+	})
+	if err != nil {
+		return InsightsResults{}, err
+	}
+
+	results := InsightsResults{Status: string(result.Status)}
+	if s := result.Statistics; s != nil {
+		results.RecordsMatched = s.RecordsMatched
+		results.RecordsScanned = s.RecordsScanned
+		results.BytesScanned = s.BytesScanned
+	}
 
-				lastEvent = currentEvent
+	seen := map[string]bool{}
+	for _, fields := range result.Results {
+		row := map[string]string{}
+		for _, f := range fields {
+			name := aws.ToString(f.Field)
+			if name == "@ptr" {
+				continue
+			}
+			row[name] = aws.ToString(f.Value)
+			if !seen[name] {
+				seen[name] = true
+				results.Fields = append(results.Fields, name)
 			}
 		}
+		results.Rows = append(results.Rows, row)
+	}
 
-		startTime = lastEvent.Timestamp
-		time.Sleep(3 * time.Second)
+	return results, nil
+}
+
+// StopQuery stops a running Logs Insights query.
+func (c *AWSClient) StopQuery(queryID string) error {
+	_, err := c.logClient.StopQuery(c.ctx, &cloudwatchlogs.StopQueryInput{QueryId: aws.String(queryID)})
+	return err
+}
+
+// LogGroupARN returns the ARN of the log group, without the trailing :* of
+// DescribeLogGroups.
+func (c *AWSClient) LogGroupARN(name string) (string, error) {
+	pager := cloudwatchlogs.NewDescribeLogGroupsPaginator(c.logClient, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(name),
+	})
+	for pager.HasMorePages() {
+		result, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return "", err
+		}
+
+		for _, g := range result.LogGroups {
+			if aws.ToString(g.LogGroupName) == name {
+				return strings.TrimSuffix(aws.ToString(g.Arn), ":*"), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("log group '%s' not found", name)
+}
+
+// LogStreamExists checks if the log group has a stream with the name.
+func (c *AWSClient) LogStreamExists(group string, name string) (bool, error) {
+	// Streams are sorted by name, so the stream itself comes first of those
+	// starting with its name.
+	result, err := c.logClient.DescribeLogStreams(c.ctx, &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName:        aws.String(group),
+		LogStreamNamePrefix: aws.String(name),
+		Limit:               aws.Int32(1),
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(result.LogStreams) > 0 && aws.ToString(result.LogStreams[0].LogStreamName) == name, nil
+}
+
+// filterLogEvents invokes fn for each event matching the query between start
+// and end. A zero end reads up to the latest event.
+func (c *AWSClient) filterLogEvents(query LogQuery, start time.Time, end time.Time, fn func(event LogEvent)) error {
+	pager := cloudwatchlogs.NewFilterLogEventsPaginator(c.logClient, filterLogEventsInput(query, start, end))
+	for pager.HasMorePages() {
+		result, err := pager.NextPage(c.ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range result.Events {
+			fn(newLogEvent(query.GroupName, event))
+		}
+	}
+
+	return nil
+}
+
+// FilterLogEventsPage returns one page of the events matching the query
+// between its StartTime and EndTime, and the token of the next page which is
+// empty after the last page.
+func (c *AWSClient) FilterLogEventsPage(query LogQuery, nextToken string) ([]LogEvent, string, error) {
+	input := filterLogEventsInput(query, query.StartTime, query.EndTime)
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+
+	result, err := c.logClient.FilterLogEvents(c.ctx, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var events []LogEvent
+	for _, event := range result.Events {
+		events = append(events, newLogEvent(query.GroupName, event))
 	}
+	return events, aws.ToString(result.NextToken), nil
+}
+
+func filterLogEventsInput(query LogQuery, start time.Time, end time.Time) *cloudwatchlogs.FilterLogEventsInput {
+	input := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String(query.GroupName),
+		StartTime:    aws.Int64(start.UnixMilli()),
+	}
+	if !end.IsZero() {
+		input.EndTime = aws.Int64(end.UnixMilli())
+	}
+	if query.FilterPattern != "" {
+		input.FilterPattern = aws.String(query.FilterPattern)
+	}
+	// FilterLogEvents doesn't accept both.
+	if len(query.StreamNames) > 0 {
+		input.LogStreamNames = query.StreamNames
+	} else if query.StreamPrefix != "" {
+		input.LogStreamNamePrefix = aws.String(query.StreamPrefix)
+	}
+	return input
+}
+
+func newLogEvent(groupName string, event cwltypes.FilteredLogEvent) LogEvent {
+	return LogEvent{
+		ID:            aws.ToString(event.EventId),
+		GroupName:     groupName,
+		StreamName:    aws.ToString(event.LogStreamName),
+		Timestamp:     time.UnixMilli(aws.ToInt64(event.Timestamp)),
+		IngestionTime: time.UnixMilli(aws.ToInt64(event.IngestionTime)),
+		Message:       aws.ToString(event.Message),
+	}
+}
+
+// readLiveTail invokes fn for each event of the session until it ends.
+func readLiveTail(stream *cloudwatchlogs.StartLiveTailEventStream, groupName string, fn func(event LogEvent)) error {
+	defer stream.Close()
+	for event := range stream.Events() {
+		update, ok := event.(*cwltypes.StartLiveTailResponseStreamMemberSessionUpdate)
+		if !ok {
+			continue
+		}
+
+		for _, e := range update.Value.SessionResults {
+			fn(LogEvent{
+				GroupName:     groupName,
+				StreamName:    aws.ToString(e.LogStreamName),
+				Timestamp:     time.UnixMilli(aws.ToInt64(e.Timestamp)),
+				IngestionTime: time.UnixMilli(aws.ToInt64(e.IngestionTime)),
+				Message:       aws.ToString(e.Message),
+			})
+		}
+	}
+	return stream.Err()
+}
+
+// recentEvents remembers the events delivered in the last minute so the
+// overlap between FilterLogEvents and a Live Tail session isn't delivered
+// twice. Live Tail events have no ID so they are compared by stream,
+// timestamp, and message.
+type recentEvents struct {
+	keys   map[string]time.Time
+	latest time.Time
+	pruned time.Time
+}
+
+// recentWindow is how long delivered events are remembered.
+const recentWindow = time.Minute
+
+// add records the event, false if it was already delivered.
+func (r *recentEvents) add(e LogEvent) bool {
+	if r.keys == nil {
+		r.keys = map[string]time.Time{}
+	}
+
+	key := fmt.Sprintf("%s\x00%d\x00%s", e.StreamName, e.Timestamp.UnixMilli(), e.Message)
+	if _, ok := r.keys[key]; ok {
+		return false
+	}
+	r.keys[key] = e.Timestamp
+
+	if e.Timestamp.After(r.latest) {
+		r.latest = e.Timestamp
+	}
+	if r.latest.Sub(r.pruned) > recentWindow {
+		r.pruned = r.latest
+		for k, t := range r.keys {
+			if t.Before(r.latest.Add(-recentWindow)) {
+				delete(r.keys, k)
+			}
+		}
+	}
+	return true
+}
+
+// last returns the timestamp of the latest event delivered.
+func (r *recentEvents) last() time.Time {
+	return r.latest
+}
+
+// ID returns the task ID, the last part of the task ARN. It has a value
+// receiver so output templates can use {{.ID}} on the tasks printed.
+func (t Task) ID() string {
+	id, _ := utils.Last(strings.Split(t.ARN, "/"))
+	return id
+}
+
+// TaskID returns the ID of the task the container belongs to.
+func (c Container) TaskID() string {
+	id, _ := utils.Last(strings.Split(c.TaskARN, "/"))
+	return id
+}
+
+// String returns the container name.
+func (c Container) String() string {
+	return c.Name
 }
 
 // SSMTarget returns a string that can be used by SSM to target this container.
@@ -348,8 +1113,7 @@ func (c *Container) SSMTarget() (string, error) {
 		return "", fmt.Errorf("container has no runtime ID, it most likely is still starting")
 	}
 
-	taskId, _ := utils.Last(strings.Split(c.TaskARN, "/"))
-	return fmt.Sprintf(ecsTargetFormat, c.ClusterName, taskId, c.RuntimeID), nil
+	return fmt.Sprintf(ecsTargetFormat, c.ClusterName, c.TaskID(), c.RuntimeID), nil
 }
 
 // isCommandAgentRunning checks if the ExecuteCommandAgent managed agent is running in the given container.
@@ -361,3 +1125,18 @@ func isCommandAgentRunning(container types.Container) bool {
 	}
 	return false
 }
+
+// attachmentDetail returns the value of the detail from the task's network interface attachment.
+func attachmentDetail(attachments []types.Attachment, name string) string {
+	for _, a := range attachments {
+		if aws.ToString(a.Type) != "ElasticNetworkInterface" {
+			continue
+		}
+		for _, d := range a.Details {
+			if aws.ToString(d.Name) == name {
+				return aws.ToString(d.Value)
+			}
+		}
+	}
+	return ""
+}
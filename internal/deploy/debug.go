@@ -0,0 +1,117 @@
+package deploy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+const (
+	// DebugContainer is the name of the container in a debug task definition.
+	DebugContainer = "debug"
+	// debugFamilySuffix is added to the service's family for debug task definitions.
+	debugFamilySuffix = "-going-debug"
+	// debugMemory is the container memory used when the task has no memory set.
+	debugMemory = 512
+)
+
+// sleep makes the container sleep for the ttl, replacing the image's
+// entrypoint as well so it doesn't run the application with sleep as its
+// arguments. The task stops by itself if it isn't stopped when the session
+// ends.
+func sleep(container *types.ContainerDefinition, ttl time.Duration) {
+	container.EntryPoint = []string{"sleep"}
+	container.Command = []string{fmt.Sprint(int(ttl.Seconds()))}
+}
+
+// DebugTaskDefinitionInput returns a task definition with a single container
+// running the image. The roles, network mode, and size are copied from the
+// service's task definition so the task has the same access as the service.
+func DebugTaskDefinitionInput(service *types.TaskDefinition, image string, ttl time.Duration) *ecs.RegisterTaskDefinitionInput {
+	container := types.ContainerDefinition{
+		Name:      aws.String(DebugContainer),
+		Image:     aws.String(image),
+		Essential: aws.Bool(true),
+		// The init process reaps the processes of exec sessions.
+		LinuxParameters: &types.LinuxParameters{InitProcessEnabled: aws.Bool(true)},
+	}
+	sleep(&container, ttl)
+	if service.Memory == nil {
+		container.Memory = aws.Int32(debugMemory)
+	}
+
+	return &ecs.RegisterTaskDefinitionInput{
+		Family:                  aws.String(aws.ToString(service.Family) + debugFamilySuffix),
+		ContainerDefinitions:    []types.ContainerDefinition{container},
+		TaskRoleArn:             service.TaskRoleArn,
+		ExecutionRoleArn:        service.ExecutionRoleArn,
+		NetworkMode:             service.NetworkMode,
+		RequiresCompatibilities: service.RequiresCompatibilities,
+		RuntimePlatform:         service.RuntimePlatform,
+		Cpu:                     service.Cpu,
+		Memory:                  service.Memory,
+	}
+}
+
+// SleepTaskDefinitionInput returns a copy of the service's task definition
+// with the container sleeping instead of running the application. RunTask
+// overrides can't replace the entrypoint, so the copy is registered in a
+// family of its own. The sleeping container would fail its health check, so
+// it has none and the other containers don't wait for it to be healthy.
+func SleepTaskDefinitionInput(service *types.TaskDefinition, container string, ttl time.Duration) *ecs.RegisterTaskDefinitionInput {
+	var containers []types.ContainerDefinition
+	for _, c := range service.ContainerDefinitions {
+		if aws.ToString(c.Name) == container {
+			sleep(&c, ttl)
+			c.HealthCheck = nil
+		}
+
+		var dependsOn []types.ContainerDependency
+		for _, d := range c.DependsOn {
+			if aws.ToString(d.ContainerName) == container && d.Condition == types.ContainerConditionHealthy {
+				continue
+			}
+			dependsOn = append(dependsOn, d)
+		}
+		c.DependsOn = dependsOn
+
+		containers = append(containers, c)
+	}
+
+	return &ecs.RegisterTaskDefinitionInput{
+		Family:                  aws.String(aws.ToString(service.Family) + debugFamilySuffix),
+		ContainerDefinitions:    containers,
+		TaskRoleArn:             service.TaskRoleArn,
+		ExecutionRoleArn:        service.ExecutionRoleArn,
+		NetworkMode:             service.NetworkMode,
+		RequiresCompatibilities: service.RequiresCompatibilities,
+		RuntimePlatform:         service.RuntimePlatform,
+		Cpu:                     service.Cpu,
+		Memory:                  service.Memory,
+		EphemeralStorage:        service.EphemeralStorage,
+		InferenceAccelerators:   service.InferenceAccelerators,
+		IpcMode:                 service.IpcMode,
+		PidMode:                 service.PidMode,
+		PlacementConstraints:    service.PlacementConstraints,
+		ProxyConfiguration:      service.ProxyConfiguration,
+		Volumes:                 service.Volumes,
+	}
+}
+
+// IsExecReady checks if the container is running and its ExecuteCommand agent
+// is ready for sessions.
+func IsExecReady(container string) func(client.Task) bool {
+	return func(t client.Task) bool {
+		for _, c := range t.Containers {
+			if c.Name == container {
+				return t.LastStatus == string(types.DesiredStatusRunning) && c.ExecuteAgentRunning
+			}
+		}
+		return false
+	}
+}
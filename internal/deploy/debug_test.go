@@ -0,0 +1,150 @@
+package deploy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+func TestDebugTaskDefinitionInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition *types.TaskDefinition
+		wantMemory *int32
+	}{
+		{
+			name: "fargate task size",
+			definition: &types.TaskDefinition{
+				Family:      aws.String("api"),
+				TaskRoleArn: aws.String("arn:aws:iam::123456789012:role/api"),
+				NetworkMode: types.NetworkModeAwsvpc,
+				Cpu:         aws.String("256"),
+				Memory:      aws.String("512"),
+			},
+			wantMemory: nil,
+		},
+		{
+			name:       "container memory without task memory",
+			definition: &types.TaskDefinition{Family: aws.String("api")},
+			wantMemory: aws.Int32(debugMemory),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DebugTaskDefinitionInput(tt.definition, "busybox", time.Hour)
+
+			if aws.ToString(got.Family) != "api-going-debug" {
+				t.Errorf("DebugTaskDefinitionInput() family = %s", aws.ToString(got.Family))
+			}
+			if got.TaskRoleArn != tt.definition.TaskRoleArn || got.Memory != tt.definition.Memory {
+				t.Errorf("DebugTaskDefinitionInput() didn't copy the task role and size")
+			}
+
+			container := got.ContainerDefinitions[0]
+			if aws.ToString(container.Image) != "busybox" || !reflect.DeepEqual(container.EntryPoint, []string{"sleep"}) ||
+				!reflect.DeepEqual(container.Command, []string{"3600"}) {
+				t.Errorf("DebugTaskDefinitionInput() container = %+v", container)
+			}
+			if !reflect.DeepEqual(container.Memory, tt.wantMemory) {
+				t.Errorf("DebugTaskDefinitionInput() container memory = %v, want %v", container.Memory, tt.wantMemory)
+			}
+		})
+	}
+}
+
+func TestSleepTaskDefinitionInput(t *testing.T) {
+	definition := &types.TaskDefinition{
+		Family:      aws.String("api"),
+		TaskRoleArn: aws.String("arn:aws:iam::123456789012:role/api"),
+		Volumes:     []types.Volume{{Name: aws.String("data")}},
+		ContainerDefinitions: []types.ContainerDefinition{
+			{
+				Name:        aws.String("web"),
+				EntryPoint:  []string{"/app/start"},
+				Command:     []string{"--port", "80"},
+				HealthCheck: &types.HealthCheck{Command: []string{"CMD", "/app/healthy"}},
+			},
+			{Name: aws.String("envoy"), EntryPoint: []string{"/envoy"}},
+			{
+				Name: aws.String("worker"),
+				DependsOn: []types.ContainerDependency{
+					{ContainerName: aws.String("web"), Condition: types.ContainerConditionHealthy},
+					{ContainerName: aws.String("envoy"), Condition: types.ContainerConditionHealthy},
+					{ContainerName: aws.String("web"), Condition: types.ContainerConditionStart},
+				},
+			},
+		},
+	}
+
+	got := SleepTaskDefinitionInput(definition, "web", time.Hour)
+
+	if aws.ToString(got.Family) != "api-going-debug" {
+		t.Errorf("SleepTaskDefinitionInput() family = %s", aws.ToString(got.Family))
+	}
+	if got.TaskRoleArn != definition.TaskRoleArn || !reflect.DeepEqual(got.Volumes, definition.Volumes) {
+		t.Errorf("SleepTaskDefinitionInput() didn't copy the task role and volumes")
+	}
+	want := []types.ContainerDefinition{
+		{Name: aws.String("web"), EntryPoint: []string{"sleep"}, Command: []string{"3600"}},
+		{Name: aws.String("envoy"), EntryPoint: []string{"/envoy"}},
+		{
+			Name: aws.String("worker"),
+			DependsOn: []types.ContainerDependency{
+				{ContainerName: aws.String("envoy"), Condition: types.ContainerConditionHealthy},
+				{ContainerName: aws.String("web"), Condition: types.ContainerConditionStart},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got.ContainerDefinitions, want) {
+		t.Errorf("SleepTaskDefinitionInput() containers = %+v, want %+v", got.ContainerDefinitions, want)
+	}
+	// The service's task definition isn't changed.
+	if !reflect.DeepEqual(definition.ContainerDefinitions[0].EntryPoint, []string{"/app/start"}) ||
+		definition.ContainerDefinitions[0].HealthCheck == nil || len(definition.ContainerDefinitions[2].DependsOn) != 3 {
+		t.Errorf("SleepTaskDefinitionInput() changed the service's task definition")
+	}
+}
+
+func TestIsExecReady(t *testing.T) {
+	tests := []struct {
+		name string
+		task client.Task
+		want bool
+	}{
+		{
+			name: "pending",
+			task: client.Task{LastStatus: "PENDING", Containers: []client.Container{{Name: "debug"}}},
+			want: false,
+		},
+		{
+			name: "running without agent",
+			task: client.Task{LastStatus: "RUNNING", Containers: []client.Container{{Name: "debug"}}},
+			want: false,
+		},
+		{
+			name: "agent running in another container",
+			task: client.Task{
+				LastStatus: "RUNNING",
+				Containers: []client.Container{{Name: "debug"}, {Name: "sidecar", ExecuteAgentRunning: true}},
+			},
+			want: false,
+		},
+		{
+			name: "ready",
+			task: client.Task{LastStatus: "RUNNING", Containers: []client.Container{{Name: "debug", ExecuteAgentRunning: true}}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExecReady("debug")(tt.task); got != tt.want {
+				t.Errorf("IsExecReady() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
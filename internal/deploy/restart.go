@@ -0,0 +1,129 @@
+package deploy
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+const restartReason = "Restarted one by one by going"
+
+// RollingRestart stops the tasks of a service one at a time, waiting for the
+// replacement task to be healthy before stopping the next one.
+type RollingRestart struct {
+	Client   *client.AWSClient
+	Cluster  string
+	Service  string
+	Interval time.Duration
+	// Timeout is how long to wait for each replacement task.
+	Timeout time.Duration
+	Out     io.Writer
+}
+
+// Run restarts every task that is running when it is called.
+func (r *RollingRestart) Run() error {
+	service, err := r.Client.DescribeService(r.Cluster, r.Service)
+	if err != nil {
+		return err
+	}
+
+	definition, err := r.Client.DescribeTaskDefinition(aws.ToString(service.TaskDefinition))
+	if err != nil {
+		return err
+	}
+	healthChecked := hasHealthCheck(definition)
+
+	original, err := r.Client.ListTasks(r.Cluster, r.Service)
+	if err != nil {
+		return err
+	}
+	if len(original) == 0 {
+		return fmt.Errorf("service '%s' has no running tasks to restart", r.Service)
+	}
+
+	originals := toSet(original)
+	for i, arn := range original {
+		task := client.Task{ARN: arn}
+		r.printf("[%d/%d] stopping task %s", i+1, len(original), task.ID())
+		if err := r.Client.StopTask(r.Cluster, arn, restartReason); err != nil {
+			return err
+		}
+
+		if err := r.waitForReplacements(originals, i+1, healthChecked); err != nil {
+			return err
+		}
+	}
+
+	r.printf("Restarted %d tasks", len(original))
+	return nil
+}
+
+// waitForReplacements waits until want tasks that weren't running when the
+// restart started are ready.
+func (r *RollingRestart) waitForReplacements(originals map[string]struct{}, want int, healthChecked bool) error {
+	deadline := time.Now().Add(r.Timeout)
+	for {
+		arns, err := r.Client.ListTasks(r.Cluster, r.Service)
+		if err != nil {
+			return err
+		}
+
+		var replacements []string
+		for _, arn := range arns {
+			if _, ok := originals[arn]; !ok {
+				replacements = append(replacements, arn)
+			}
+		}
+
+		tasks, err := r.Client.DescribeTasks(r.Cluster, replacements...)
+		if err != nil {
+			return err
+		}
+
+		ready := 0
+		for _, t := range tasks {
+			if isReady(t, healthChecked) {
+				ready++
+			}
+		}
+		if ready >= want {
+			r.printf("%d replacement tasks ready", ready)
+			return nil
+		}
+
+		if r.Timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s, %d of %d replacement tasks are ready", ErrTimeout, r.Timeout, ready, want)
+		}
+		time.Sleep(r.Interval)
+	}
+}
+
+func (r *RollingRestart) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(r.Out, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// isReady a task is ready when it is running and healthy. Tasks without any
+// health checks stay UNKNOWN so running is enough.
+func isReady(t client.Task, healthChecked bool) bool {
+	if t.LastStatus != "RUNNING" {
+		return false
+	}
+	if !healthChecked {
+		return true
+	}
+	return t.Health == string(types.HealthStatusHealthy)
+}
+
+func hasHealthCheck(definition *types.TaskDefinition) bool {
+	for _, c := range definition.ContainerDefinitions {
+		if c.HealthCheck != nil {
+			return true
+		}
+	}
+	return false
+}
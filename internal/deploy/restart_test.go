@@ -0,0 +1,48 @@
+package deploy
+
+import (
+	"testing"
+
+	"going/internal/client"
+)
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name          string
+		task          client.Task
+		healthChecked bool
+		want          bool
+	}{
+		{
+			name:          "running without health checks",
+			task:          client.Task{LastStatus: "RUNNING", Health: "UNKNOWN"},
+			healthChecked: false,
+			want:          true,
+		},
+		{
+			name:          "running but health unknown",
+			task:          client.Task{LastStatus: "RUNNING", Health: "UNKNOWN"},
+			healthChecked: true,
+			want:          false,
+		},
+		{
+			name:          "running and healthy",
+			task:          client.Task{LastStatus: "RUNNING", Health: "HEALTHY"},
+			healthChecked: true,
+			want:          true,
+		},
+		{
+			name:          "still provisioning",
+			task:          client.Task{LastStatus: "PROVISIONING"},
+			healthChecked: false,
+			want:          false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isReady(tt.task, tt.healthChecked); got != tt.want {
+				t.Errorf("isReady() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
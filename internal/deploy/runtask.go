@@ -0,0 +1,144 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+// startedBy marks the one-off tasks started by going.
+const startedBy = "going"
+
+// TaskOverrides change the command and environment of one container of a one-off task.
+type TaskOverrides struct {
+	Container   string
+	Command     []string
+	Environment map[string]string
+	// EnableExecuteCommand allows connecting to the task with ExecuteCommand.
+	EnableExecuteCommand bool
+}
+
+// RunTaskInput returns the input to run a one-off task with the service's task
+// definition, network configuration, and launch type or capacity providers.
+func RunTaskInput(cluster string, service types.Service, o TaskOverrides) *ecs.RunTaskInput {
+	input := &ecs.RunTaskInput{
+		Cluster:              aws.String(cluster),
+		TaskDefinition:       service.TaskDefinition,
+		NetworkConfiguration: service.NetworkConfiguration,
+		PlatformVersion:      service.PlatformVersion,
+		EnableECSManagedTags: service.EnableECSManagedTags,
+		EnableExecuteCommand: o.EnableExecuteCommand,
+		StartedBy:            aws.String(startedBy),
+		Count:                aws.Int32(1),
+	}
+
+	// RunTask only accepts one of them.
+	if len(service.CapacityProviderStrategy) > 0 {
+		input.CapacityProviderStrategy = service.CapacityProviderStrategy
+	} else {
+		input.LaunchType = service.LaunchType
+	}
+
+	// Services can also propagate their own tags which RunTask doesn't support.
+	if service.PropagateTags == types.PropagateTagsTaskDefinition {
+		input.PropagateTags = service.PropagateTags
+	}
+
+	if len(o.Command) == 0 && len(o.Environment) == 0 {
+		return input
+	}
+
+	override := types.ContainerOverride{Name: aws.String(o.Container), Command: o.Command}
+	var names []string
+	for name := range o.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		override.Environment = append(override.Environment, types.KeyValuePair{
+			Name:  aws.String(name),
+			Value: aws.String(o.Environment[name]),
+		})
+	}
+	input.Overrides = &types.TaskOverride{ContainerOverrides: []types.ContainerOverride{override}}
+
+	return input
+}
+
+// How long and how often a task that was just started is looked for before
+// it's an error that it's missing.
+const (
+	missingTaskGrace    = 15 * time.Second
+	missingTaskInterval = time.Second
+)
+
+// TaskWaiter polls a one-off task until it reaches a state.
+type TaskWaiter struct {
+	Client   *client.AWSClient
+	Cluster  string
+	Interval time.Duration
+	Timeout  time.Duration
+	Out      io.Writer
+}
+
+// Wait polls the task until done returns true, printing each change of the task's status.
+func (w *TaskWaiter) Wait(taskARN string, done func(client.Task) bool) (client.Task, error) {
+	start := time.Now()
+	deadline := start.Add(w.Timeout)
+	var last string
+	for {
+		task, err := w.Client.DescribeTask(w.Cluster, taskARN)
+		// ECS is eventually consistent, a task that just started may not be found yet.
+		if errors.Is(err, client.ErrTaskNotFound) && time.Since(start) < missingTaskGrace {
+			time.Sleep(missingTaskInterval)
+			continue
+		}
+		if err != nil {
+			return task, err
+		}
+
+		if task.LastStatus != last {
+			w.printf("task %s is %s", task.ID(), task.LastStatus)
+			last = task.LastStatus
+		}
+
+		if done(task) {
+			return task, nil
+		}
+		if IsStopped(task) {
+			return task, fmt.Errorf("task %s stopped: %s", task.ID(), StopDescription(task))
+		}
+
+		if w.Timeout > 0 && time.Now().After(deadline) {
+			return task, fmt.Errorf("%w after %s, task %s is %s", ErrTimeout, w.Timeout, task.ID(), task.LastStatus)
+		}
+		time.Sleep(w.Interval)
+	}
+}
+
+func (w *TaskWaiter) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(w.Out, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// IsStopped checks if the task has stopped.
+func IsStopped(t client.Task) bool {
+	return t.LastStatus == string(types.DesiredStatusStopped)
+}
+
+// ExitCode returns the exit code of the container in the task, false if it hasn't exited.
+func ExitCode(t client.Task, container string) (int32, bool) {
+	for _, c := range t.Containers {
+		if c.Name == container && c.ExitCode != nil {
+			return *c.ExitCode, true
+		}
+	}
+	return 0, false
+}
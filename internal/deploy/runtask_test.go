@@ -0,0 +1,85 @@
+package deploy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestRunTaskInput(t *testing.T) {
+	network := &types.NetworkConfiguration{
+		AwsvpcConfiguration: &types.AwsVpcConfiguration{
+			Subnets:        []string{"subnet-1"},
+			SecurityGroups: []string{"sg-1"},
+		},
+	}
+	strategy := []types.CapacityProviderStrategyItem{{CapacityProvider: aws.String("FARGATE_SPOT"), Weight: 1}}
+
+	tests := []struct {
+		name      string
+		service   types.Service
+		overrides TaskOverrides
+		want      *ecs.RunTaskInput
+	}{
+		{
+			name: "launch type",
+			service: types.Service{
+				TaskDefinition:       aws.String("api:3"),
+				NetworkConfiguration: network,
+				LaunchType:           types.LaunchTypeFargate,
+				PlatformVersion:      aws.String("LATEST"),
+				PropagateTags:        types.PropagateTagsService,
+			},
+			want: &ecs.RunTaskInput{
+				Cluster:              aws.String("main"),
+				TaskDefinition:       aws.String("api:3"),
+				NetworkConfiguration: network,
+				LaunchType:           types.LaunchTypeFargate,
+				PlatformVersion:      aws.String("LATEST"),
+				StartedBy:            aws.String("going"),
+				Count:                aws.Int32(1),
+			},
+		},
+		{
+			name: "capacity providers and overrides",
+			service: types.Service{
+				TaskDefinition:           aws.String("api:3"),
+				CapacityProviderStrategy: strategy,
+				PropagateTags:            types.PropagateTagsTaskDefinition,
+			},
+			overrides: TaskOverrides{
+				Container:            "web",
+				Command:              []string{"./manage.py", "migrate"},
+				Environment:          map[string]string{"B": "2", "A": "1"},
+				EnableExecuteCommand: true,
+			},
+			want: &ecs.RunTaskInput{
+				Cluster:                  aws.String("main"),
+				TaskDefinition:           aws.String("api:3"),
+				CapacityProviderStrategy: strategy,
+				PropagateTags:            types.PropagateTagsTaskDefinition,
+				EnableExecuteCommand:     true,
+				StartedBy:                aws.String("going"),
+				Count:                    aws.Int32(1),
+				Overrides: &types.TaskOverride{ContainerOverrides: []types.ContainerOverride{{
+					Name:    aws.String("web"),
+					Command: []string{"./manage.py", "migrate"},
+					Environment: []types.KeyValuePair{
+						{Name: aws.String("A"), Value: aws.String("1")},
+						{Name: aws.String("B"), Value: aws.String("2")},
+					},
+				}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RunTaskInput("main", tt.service, tt.overrides); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RunTaskInput() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
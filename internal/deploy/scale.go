@@ -0,0 +1,188 @@
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+	"going/internal/goingconfig"
+	"going/internal/utils"
+)
+
+// ErrCancelled is returned when the user doesn't confirm a change.
+var ErrCancelled = errors.New("cancelled")
+
+// productionTagKeys and productionTagValues are the tags that mark a service as
+// production, compared case-insensitively.
+var (
+	productionTagKeys   = []string{"env", "environment", "stage"}
+	productionTagValues = []string{"prod", "production"}
+)
+
+// Scaler changes the desired count of a service.
+type Scaler struct {
+	Client   *client.AWSClient
+	Cluster  string
+	Service  string
+	Interval time.Duration
+	Timeout  time.Duration
+	Out      io.Writer
+	// Confirm is asked before scaling a service tagged as production.
+	Confirm func(label string) (bool, error)
+}
+
+// Scale sets the desired count of the service and returns the previous desired count.
+func (s *Scaler) Scale(count int32) (int32, error) {
+	service, err := s.Client.DescribeServiceWithTags(s.Cluster, s.Service)
+	if err != nil {
+		return 0, err
+	}
+
+	previous := service.DesiredCount
+	if previous == count {
+		s.printf("Service %s already has a desired count of %d", s.Service, count)
+		return previous, nil
+	}
+
+	bounds, err := s.Client.ScalingBounds(s.Cluster, s.Service)
+	if err != nil {
+		return previous, err
+	}
+	if bounds != nil && (count < bounds.Min || count > bounds.Max) {
+		s.printf("Warning: Application Auto Scaling keeps the service between %d and %d tasks, "+
+			"it will override a desired count of %d", bounds.Min, bounds.Max, count)
+	}
+
+	if IsProduction(service.Tags) {
+		label := fmt.Sprintf("Service %s is tagged as production, scale it from %d to %d", s.Service, previous, count)
+		yes, err := s.Confirm(label)
+		if err != nil {
+			return previous, err
+		}
+		if !yes {
+			return previous, ErrCancelled
+		}
+	}
+
+	err = s.Client.UpdateService(&ecs.UpdateServiceInput{
+		Cluster:      aws.String(s.Cluster),
+		Service:      aws.String(s.Service),
+		DesiredCount: aws.Int32(count),
+	})
+	if err != nil {
+		return previous, err
+	}
+
+	s.printf("Scaling service %s from %d to %d", s.Service, previous, count)
+	return previous, nil
+}
+
+// Wait polls the service until the running count matches the count.
+func (s *Scaler) Wait(count int32) error {
+	deadline := time.Now().Add(s.Timeout)
+	var last string
+	for {
+		service, err := s.Client.DescribeService(s.Cluster, s.Service)
+		if err != nil {
+			return err
+		}
+
+		progress := fmt.Sprintf("%d/%d running, %d pending", service.RunningCount, count, service.PendingCount)
+		if progress != last {
+			s.printf("%s", progress)
+			last = progress
+		}
+
+		if service.RunningCount == count && service.PendingCount == 0 {
+			return nil
+		}
+
+		if s.Timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s, %s", ErrTimeout, s.Timeout, progress)
+		}
+		time.Sleep(s.Interval)
+	}
+}
+
+func (s *Scaler) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(s.Out, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// IsProduction checks the tags for an environment tag with a production value.
+func IsProduction(tags []types.Tag) bool {
+	for _, t := range tags {
+		key := strings.ToLower(aws.ToString(t.Key))
+		value := strings.ToLower(aws.ToString(t.Value))
+		if contains(productionTagKeys, key) && contains(productionTagValues, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, s string) bool {
+	for _, i := range items {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ScaleState remembers the desired count of services before they were scaled
+// to zero so they can be restored.
+type ScaleState struct {
+	Counts map[string]int32 `json:"counts"`
+
+	filename string
+}
+
+// ScaleStateKey identifies a service in the scale state.
+func ScaleStateKey(profile string, region string, cluster string, service string) string {
+	return strings.Join([]string{profile, region, cluster, service}, "/")
+}
+
+// ReadScaleState loads the scale state from filename. A missing file is an empty state.
+func ReadScaleState(filename string) (ScaleState, error) {
+	s := ScaleState{Counts: map[string]int32{}, filename: filename}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read scale state file, %w", err)
+	}
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, fmt.Errorf("failed to parse scale state file, %w", err)
+	}
+	if s.Counts == nil {
+		s.Counts = map[string]int32{}
+	}
+
+	return s, nil
+}
+
+// Write stores the scale state in its file.
+func (s *ScaleState) Write() error {
+	if err := os.MkdirAll(filepath.Dir(s.filename), 0700); err != nil {
+		return err
+	}
+	return utils.StoreCacheFile(s.filename, s, 0600)
+}
+
+// ScaleStateFilename returns the path to the scale state file.
+func ScaleStateFilename() string {
+	return filepath.Join(goingconfig.Dir(), "scale-state.json")
+}
@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestIsProduction(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []types.Tag
+		want bool
+	}{
+		{
+			name: "no tags",
+			tags: nil,
+			want: false,
+		},
+		{
+			name: "environment production",
+			tags: []types.Tag{{Key: aws.String("Environment"), Value: aws.String("Production")}},
+			want: true,
+		},
+		{
+			name: "env prod",
+			tags: []types.Tag{{Key: aws.String("team"), Value: aws.String("web")}, {Key: aws.String("env"), Value: aws.String("prod")}},
+			want: true,
+		},
+		{
+			name: "staging",
+			tags: []types.Tag{{Key: aws.String("env"), Value: aws.String("staging")}},
+			want: false,
+		},
+		{
+			name: "prod value on another key",
+			tags: []types.Tag{{Key: aws.String("name"), Value: aws.String("prod")}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsProduction(tt.tags); got != tt.want {
+				t.Errorf("IsProduction() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleState(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "going", "scale-state.json")
+	key := ScaleStateKey("prod", "us-east-1", "main", "worker")
+
+	s, err := ReadScaleState(filename)
+	if err != nil {
+		t.Fatalf("ReadScaleState() of missing file error = %v", err)
+	}
+	s.Counts[key] = 3
+	if err := s.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	s, err = ReadScaleState(filename)
+	if err != nil {
+		t.Fatalf("ReadScaleState() error = %v", err)
+	}
+	if got := s.Counts[key]; got != 3 {
+		t.Errorf("ReadScaleState() count = %d, want 3", got)
+	}
+}
@@ -0,0 +1,322 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+	"going/internal/utils"
+)
+
+const primaryStatus = "PRIMARY"
+
+var (
+	// ErrDeploymentFailed is returned when the deployment's rollout state is FAILED.
+	ErrDeploymentFailed = errors.New("deployment failed")
+	// ErrTimeout is returned when the deployment doesn't finish before the timeout.
+	ErrTimeout = errors.New("timed out waiting for the deployment")
+)
+
+// Watcher follows the primary deployment of a service, printing service
+// events, deployment changes, and tasks starting and stopping until the
+// deployment completes, fails, or the timeout is reached.
+type Watcher struct {
+	Client   *client.AWSClient
+	Cluster  string
+	Service  string
+	Interval time.Duration
+	Timeout  time.Duration
+	Out      io.Writer
+
+	since       time.Time
+	deployments map[string]deployment
+	events      map[string]struct{}
+	running     map[string]struct{}
+	stopped     map[string]struct{}
+}
+
+// deployment the parts of a types.Deployment that are compared between polls.
+type deployment struct {
+	ID             string
+	Status         string
+	RolloutState   string
+	RolloutReason  string
+	TaskDefinition string
+	Desired        int32
+	Running        int32
+	Pending        int32
+	Failed         int32
+}
+
+// Watch polls the service until the primary deployment is done. It returns
+// ErrDeploymentFailed or ErrTimeout when the deployment doesn't complete.
+func (w *Watcher) Watch() error {
+	deadline := time.Now().Add(w.Timeout)
+	first := true
+
+	for {
+		service, err := w.Client.DescribeService(w.Cluster, w.Service)
+		if err != nil {
+			return err
+		}
+
+		current := deploymentsOf(service)
+		primary, ok := primaryOf(current)
+		if !ok {
+			return fmt.Errorf("service '%s' has no primary deployment", w.Service)
+		}
+
+		if first {
+			// Show the events from the start of the deployment being watched.
+			w.since = primaryCreatedAt(service)
+			w.events = map[string]struct{}{}
+			w.printf("Watching deployment %s of service %s (%s)", primary.ID, w.Service, primary.TaskDefinition)
+		}
+
+		w.printEvents(service.Events)
+		for _, change := range diffDeployments(w.deployments, current) {
+			w.printf("%s", change)
+		}
+		w.deployments = current
+
+		if err := w.trackTasks(first); err != nil {
+			return err
+		}
+		first = false
+
+		done, err := isDone(primary, len(current))
+		if done {
+			if err == nil {
+				w.printf("Deployment %s completed", primary.ID)
+			}
+			return err
+		}
+
+		if w.Timeout > 0 && time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s, deployment %s is %s", ErrTimeout, w.Timeout, primary.ID,
+				primary.RolloutState)
+		}
+		time.Sleep(w.Interval)
+	}
+}
+
+func (w *Watcher) printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(w.Out, "%s %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, a...))
+}
+
+// printEvents prints the service events that haven't been seen yet, oldest first.
+func (w *Watcher) printEvents(events []types.ServiceEvent) {
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		id := aws.ToString(e.Id)
+		created := aws.ToTime(e.CreatedAt)
+		if _, ok := w.events[id]; ok || created.Before(w.since) {
+			continue
+		}
+		w.events[id] = struct{}{}
+		_, _ = fmt.Fprintf(w.Out, "%s event: %s\n", created.Local().Format(time.TimeOnly), aws.ToString(e.Message))
+	}
+}
+
+// trackTasks prints the tasks that started or stopped since the last poll.
+// The first poll only records the current tasks.
+func (w *Watcher) trackTasks(first bool) error {
+	running, err := w.Client.ListTasks(w.Cluster, w.Service)
+	if err != nil {
+		return err
+	}
+	stopped, err := w.Client.ListStoppedTasks(w.Cluster, w.Service)
+	if err != nil {
+		return err
+	}
+
+	if first {
+		w.running = toSet(running)
+		w.stopped = toSet(stopped)
+		return nil
+	}
+
+	started := newItems(w.running, running)
+	if len(started) > 0 {
+		tasks, err := w.Client.DescribeTasks(w.Cluster, started...)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			w.printf("task %s started (%s) %s", t.ID(), definitionName(t.DefinitionARN), t.LastStatus)
+		}
+	}
+
+	ended := newItems(w.stopped, stopped)
+	if len(ended) > 0 {
+		tasks, err := w.Client.DescribeTasks(w.Cluster, ended...)
+		if err != nil {
+			return err
+		}
+		for _, t := range tasks {
+			w.printf("task %s stopped: %s", t.ID(), StopDescription(t))
+		}
+	}
+
+	return nil
+}
+
+// StopDescription explains why the task stopped including the exit code of each container.
+func StopDescription(t client.Task) string {
+	parts := []string{t.StoppedReason}
+	if t.StopCode != "" {
+		parts[0] = fmt.Sprintf("%s (%s)", t.StoppedReason, t.StopCode)
+	}
+
+	for _, c := range t.Containers {
+		switch {
+		case c.ExitCode != nil && c.Reason != "":
+			parts = append(parts, fmt.Sprintf("%s exited %d: %s", c.Name, *c.ExitCode, c.Reason))
+		case c.ExitCode != nil:
+			parts = append(parts, fmt.Sprintf("%s exited %d", c.Name, *c.ExitCode))
+		case c.Reason != "":
+			parts = append(parts, fmt.Sprintf("%s: %s", c.Name, c.Reason))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// isDone checks if the primary deployment has finished, returning an error if it failed.
+func isDone(primary deployment, count int) (bool, error) {
+	switch types.DeploymentRolloutState(primary.RolloutState) {
+	case types.DeploymentRolloutStateCompleted:
+		return true, nil
+	case types.DeploymentRolloutStateFailed:
+		return true, fmt.Errorf("%w: %s", ErrDeploymentFailed, primary.RolloutReason)
+	case "":
+		// Services without a rollout state are done when the old deployments are gone.
+		return count == 1 && primary.Running == primary.Desired && primary.Pending == 0, nil
+	}
+	return false, nil
+}
+
+// diffDeployments describes how the deployments changed between polls.
+func diffDeployments(prev map[string]deployment, cur map[string]deployment) []string {
+	var changes []string
+	for _, id := range sortedIDs(cur) {
+		d := cur[id]
+		p, ok := prev[id]
+		if !ok {
+			if prev != nil {
+				changes = append(changes, fmt.Sprintf("deployment %s created: %s %s (%s)", id, d.Status,
+					d.RolloutState, d.TaskDefinition))
+			}
+			changes = append(changes, countsOf(d))
+			continue
+		}
+
+		if p.Status != d.Status {
+			changes = append(changes, fmt.Sprintf("deployment %s status %s -> %s", id, p.Status, d.Status))
+		}
+		if p.RolloutState != d.RolloutState {
+			change := fmt.Sprintf("deployment %s rollout %s -> %s", id, p.RolloutState, d.RolloutState)
+			if d.RolloutReason != "" {
+				change += ": " + d.RolloutReason
+			}
+			changes = append(changes, change)
+		}
+		if p.Desired != d.Desired || p.Running != d.Running || p.Pending != d.Pending || p.Failed != d.Failed {
+			changes = append(changes, countsOf(d))
+		}
+	}
+
+	for _, id := range sortedIDs(prev) {
+		if _, ok := cur[id]; !ok {
+			changes = append(changes, fmt.Sprintf("deployment %s finished draining", id))
+		}
+	}
+
+	return changes
+}
+
+func countsOf(d deployment) string {
+	s := fmt.Sprintf("deployment %s %s: %d/%d running, %d pending", d.ID, d.Status, d.Running, d.Desired, d.Pending)
+	if d.Failed > 0 {
+		s += fmt.Sprintf(", %d failed", d.Failed)
+	}
+	return s
+}
+
+func deploymentsOf(service types.Service) map[string]deployment {
+	deployments := map[string]deployment{}
+	for _, d := range service.Deployments {
+		id := aws.ToString(d.Id)
+		deployments[id] = deployment{
+			ID:             id,
+			Status:         aws.ToString(d.Status),
+			RolloutState:   string(d.RolloutState),
+			RolloutReason:  aws.ToString(d.RolloutStateReason),
+			TaskDefinition: definitionName(aws.ToString(d.TaskDefinition)),
+			Desired:        d.DesiredCount,
+			Running:        d.RunningCount,
+			Pending:        d.PendingCount,
+			Failed:         d.FailedTasks,
+		}
+	}
+	return deployments
+}
+
+func primaryOf(deployments map[string]deployment) (deployment, bool) {
+	for _, d := range deployments {
+		if d.Status == primaryStatus {
+			return d, true
+		}
+	}
+	return deployment{}, false
+}
+
+func primaryCreatedAt(service types.Service) time.Time {
+	for _, d := range service.Deployments {
+		if aws.ToString(d.Status) == primaryStatus {
+			return aws.ToTime(d.CreatedAt)
+		}
+	}
+	return time.Now()
+}
+
+// definitionName returns the family and revision of the task definition ARN.
+func definitionName(arn string) string {
+	name, _ := utils.Last(strings.Split(arn, "/"))
+	return name
+}
+
+func sortedIDs(m map[string]deployment) []string {
+	var ids []string
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func toSet(items []string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, i := range items {
+		set[i] = struct{}{}
+	}
+	return set
+}
+
+// newItems returns the items not in seen, adding them to seen.
+func newItems(seen map[string]struct{}, items []string) []string {
+	var added []string
+	for _, i := range items {
+		if _, ok := seen[i]; !ok {
+			seen[i] = struct{}{}
+			added = append(added, i)
+		}
+	}
+	return added
+}
@@ -0,0 +1,134 @@
+package deploy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"going/internal/client"
+)
+
+func TestIsDone(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary deployment
+		count   int
+		want    bool
+		wantErr error
+	}{
+		{
+			name:    "completed",
+			primary: deployment{RolloutState: "COMPLETED"},
+			count:   1,
+			want:    true,
+		},
+		{
+			name:    "in progress",
+			primary: deployment{RolloutState: "IN_PROGRESS"},
+			count:   2,
+			want:    false,
+		},
+		{
+			name:    "failed",
+			primary: deployment{RolloutState: "FAILED", RolloutReason: "circuit breaker"},
+			count:   2,
+			want:    true,
+			wantErr: ErrDeploymentFailed,
+		},
+		{
+			name:    "no rollout state and steady",
+			primary: deployment{Desired: 2, Running: 2},
+			count:   1,
+			want:    true,
+		},
+		{
+			name:    "no rollout state with old deployment",
+			primary: deployment{Desired: 2, Running: 2},
+			count:   2,
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isDone(tt.primary, tt.count)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("isDone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isDone() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffDeployments(t *testing.T) {
+	primary := deployment{ID: "ecs-svc/1", Status: "PRIMARY", RolloutState: "IN_PROGRESS", TaskDefinition: "api:2",
+		Desired: 2, Running: 1}
+	completed := primary
+	completed.RolloutState = "COMPLETED"
+	completed.Running = 2
+	old := deployment{ID: "ecs-svc/0", Status: "ACTIVE", RolloutState: "COMPLETED", TaskDefinition: "api:1"}
+
+	tests := []struct {
+		name string
+		prev map[string]deployment
+		cur  map[string]deployment
+		want []string
+	}{
+		{
+			name: "first poll only shows counts",
+			prev: nil,
+			cur:  map[string]deployment{primary.ID: primary},
+			want: []string{"deployment ecs-svc/1 PRIMARY: 1/2 running, 0 pending"},
+		},
+		{
+			name: "no changes",
+			prev: map[string]deployment{primary.ID: primary},
+			cur:  map[string]deployment{primary.ID: primary},
+			want: nil,
+		},
+		{
+			name: "new deployment",
+			prev: map[string]deployment{},
+			cur:  map[string]deployment{primary.ID: primary},
+			want: []string{
+				"deployment ecs-svc/1 created: PRIMARY IN_PROGRESS (api:2)",
+				"deployment ecs-svc/1 PRIMARY: 1/2 running, 0 pending",
+			},
+		},
+		{
+			name: "rollout completes and old deployment drains",
+			prev: map[string]deployment{primary.ID: primary, old.ID: old},
+			cur:  map[string]deployment{completed.ID: completed},
+			want: []string{
+				"deployment ecs-svc/1 rollout IN_PROGRESS -> COMPLETED",
+				"deployment ecs-svc/1 PRIMARY: 2/2 running, 0 pending",
+				"deployment ecs-svc/0 finished draining",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffDeployments(tt.prev, tt.cur); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffDeployments() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopDescription(t *testing.T) {
+	one := int32(1)
+	task := client.Task{
+		StoppedReason: "Essential container in task exited",
+		StopCode:      "EssentialContainerExited",
+		Containers: []client.Container{
+			{Name: "web", ExitCode: &one},
+			{Name: "sidecar", Reason: "OutOfMemoryError"},
+		},
+	}
+
+	want := "Essential container in task exited (EssentialContainerExited); web exited 1; sidecar: OutOfMemoryError"
+	if got := StopDescription(task); got != want {
+		t.Errorf("StopDescription() got = %q, want %q", got, want)
+	}
+}
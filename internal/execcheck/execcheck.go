@@ -0,0 +1,211 @@
+package execcheck
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/manifoldco/promptui"
+
+	"going/internal/client"
+	"going/internal/utils"
+)
+
+// The outcomes of a check.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// minFargatePlatform is the first Linux Fargate platform version supporting ExecuteCommand.
+var minFargatePlatform = []int{1, 4, 0}
+
+// TaskRoleActions are the permissions the task role needs for ExecuteCommand sessions.
+var TaskRoleActions = []string{
+	"ssmmessages:CreateControlChannel",
+	"ssmmessages:CreateDataChannel",
+	"ssmmessages:OpenControlChannel",
+	"ssmmessages:OpenDataChannel",
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// PolicySimulator evaluates the IAM policies of a principal.
+type PolicySimulator interface {
+	SimulatePrincipalPolicy(principalARN string, resourceARNs []string, actions ...string) (map[string]string, error)
+}
+
+// Failed checks if any of the results failed.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes the results as a checklist. The icons are only colored when
+// stdout is a terminal.
+func Print(w io.Writer, results []Result) {
+	for _, r := range results {
+		var icon string
+		switch r.Status {
+		case StatusPass:
+			icon = utils.Styled(promptui.Styler(promptui.FGGreen))("✔")
+		case StatusWarn:
+			icon = utils.Styled(promptui.Styler(promptui.FGYellow))("!")
+		case StatusFail:
+			icon = utils.Styled(promptui.Styler(promptui.FGRed))("✘")
+		default:
+			icon = utils.Styled(promptui.Styler(promptui.FGFaint))("-")
+		}
+
+		_, _ = fmt.Fprintf(w, "%s %s\n", icon, r.Name)
+		if r.Detail != "" {
+			for _, line := range strings.Split(r.Detail, "\n") {
+				_, _ = fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+	}
+}
+
+// ServiceEnabled checks the service's EnableExecuteCommand flag.
+func ServiceEnabled(service types.Service) Result {
+	r := Result{Name: "ExecuteCommand is enabled on the service"}
+	if service.EnableExecuteCommand {
+		r.Status = StatusPass
+		return r
+	}
+
+	r.Status = StatusFail
+	r.Detail = "Run `going exec enable` to enable it."
+	return r
+}
+
+// PlatformVersion checks that Fargate services use a platform version supporting ExecuteCommand.
+func PlatformVersion(service types.Service) Result {
+	r := Result{Name: "Platform version supports ExecuteCommand"}
+	if !isFargate(service) {
+		r.Status = StatusSkip
+		r.Detail = "Not a Fargate service, EC2 container instances need ECS agent 1.50.2 or later."
+		return r
+	}
+
+	version := aws.ToString(service.PlatformVersion)
+	if version == "" || version == "LATEST" || strings.Contains(strings.ToUpper(aws.ToString(service.PlatformFamily)), "WINDOWS") {
+		r.Status = StatusPass
+		return r
+	}
+
+	if compareVersions(version, minFargatePlatform) < 0 {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("Platform version %s is older than 1.4.0, update the service to use LATEST.", version)
+		return r
+	}
+
+	r.Status = StatusPass
+	return r
+}
+
+// TaskRole checks that the task role allows the SSM actions used by ExecuteCommand.
+func TaskRole(definition *types.TaskDefinition, simulator PolicySimulator) Result {
+	r := Result{Name: "Task role allows the ssmmessages actions"}
+	roleARN := aws.ToString(definition.TaskRoleArn)
+	if roleARN == "" {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("Task definition %s:%d has no task role.", aws.ToString(definition.Family), definition.Revision)
+		return r
+	}
+
+	decisions, err := simulator.SimulatePrincipalPolicy(roleARN, nil, TaskRoleActions...)
+	if err != nil {
+		r.Status = StatusWarn
+		r.Detail = fmt.Sprintf("Couldn't simulate the policies of %s: %s", roleARN, err)
+		return r
+	}
+
+	var denied []string
+	for _, action := range TaskRoleActions {
+		if decisions[action] != "allowed" {
+			denied = append(denied, action)
+		}
+	}
+	if len(denied) > 0 {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("%s isn't allowed: %s", roleARN, strings.Join(denied, ", "))
+		return r
+	}
+
+	r.Status = StatusPass
+	return r
+}
+
+// TaskAgents checks that the managed ExecuteCommand agent is running in the containers of the tasks.
+func TaskAgents(tasks []client.Task) Result {
+	r := Result{Name: "ExecuteCommand agent is running in the tasks"}
+	if len(tasks) == 0 {
+		r.Status = StatusSkip
+		r.Detail = "No tasks are running."
+		return r
+	}
+
+	var missing []string
+	for _, t := range tasks {
+		for _, c := range t.Containers {
+			if !c.ExecuteAgentRunning {
+				missing = append(missing, fmt.Sprintf("%s/%s", t.ID(), c.Name))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("Not running in %s.\nTasks started before ExecuteCommand was enabled need a new deployment.",
+			strings.Join(missing, ", "))
+		return r
+	}
+
+	r.Status = StatusPass
+	return r
+}
+
+func isFargate(service types.Service) bool {
+	if service.LaunchType == types.LaunchTypeFargate {
+		return true
+	}
+	for _, s := range service.CapacityProviderStrategy {
+		if strings.HasPrefix(aws.ToString(s.CapacityProvider), "FARGATE") {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVersions compares a dotted version to the parts of another. Parts
+// that aren't numbers are treated as zero.
+func compareVersions(version string, other []int) int {
+	parts := strings.Split(version, ".")
+	for i, o := range other {
+		v := 0
+		if i < len(parts) {
+			v, _ = strconv.Atoi(parts[i])
+		}
+		switch {
+		case v < o:
+			return -1
+		case v > o:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package execcheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+type fakeSimulator struct {
+	decisions map[string]string
+	err       error
+}
+
+func (s fakeSimulator) SimulatePrincipalPolicy(principalARN string, resourceARNs []string, actions ...string) (map[string]string, error) {
+	return s.decisions, s.err
+}
+
+func TestPlatformVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		service types.Service
+		want    string
+	}{
+		{
+			name:    "ec2",
+			service: types.Service{LaunchType: types.LaunchTypeEc2},
+			want:    StatusSkip,
+		},
+		{
+			name:    "fargate latest",
+			service: types.Service{LaunchType: types.LaunchTypeFargate, PlatformVersion: aws.String("LATEST")},
+			want:    StatusPass,
+		},
+		{
+			name:    "fargate 1.3.0",
+			service: types.Service{LaunchType: types.LaunchTypeFargate, PlatformVersion: aws.String("1.3.0")},
+			want:    StatusFail,
+		},
+		{
+			name: "fargate spot 1.4.0",
+			service: types.Service{
+				CapacityProviderStrategy: []types.CapacityProviderStrategyItem{{CapacityProvider: aws.String("FARGATE_SPOT")}},
+				PlatformVersion:          aws.String("1.4.0"),
+			},
+			want: StatusPass,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlatformVersion(tt.service); got.Status != tt.want {
+				t.Errorf("PlatformVersion() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRole(t *testing.T) {
+	allowed := map[string]string{}
+	for _, a := range TaskRoleActions {
+		allowed[a] = "allowed"
+	}
+	partial := map[string]string{
+		"ssmmessages:CreateControlChannel": "allowed",
+		"ssmmessages:CreateDataChannel":    "implicitDeny",
+	}
+	role := &types.TaskDefinition{TaskRoleArn: aws.String("arn:aws:iam::123456789012:role/api")}
+
+	tests := []struct {
+		name       string
+		definition *types.TaskDefinition
+		simulator  fakeSimulator
+		want       string
+	}{
+		{
+			name:       "no task role",
+			definition: &types.TaskDefinition{Family: aws.String("api")},
+			want:       StatusFail,
+		},
+		{
+			name:       "allowed",
+			definition: role,
+			simulator:  fakeSimulator{decisions: allowed},
+			want:       StatusPass,
+		},
+		{
+			name:       "denied",
+			definition: role,
+			simulator:  fakeSimulator{decisions: partial},
+			want:       StatusFail,
+		},
+		{
+			name:       "simulation not permitted",
+			definition: role,
+			simulator:  fakeSimulator{err: errors.New("AccessDenied")},
+			want:       StatusWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TaskRole(tt.definition, tt.simulator); got.Status != tt.want {
+				t.Errorf("TaskRole() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskAgents(t *testing.T) {
+	ready := client.Task{ARN: "arn/1", Containers: []client.Container{{Name: "web", ExecuteAgentRunning: true}}}
+	missing := client.Task{ARN: "arn/2", Containers: []client.Container{{Name: "web"}}}
+
+	tests := []struct {
+		name  string
+		tasks []client.Task
+		want  string
+	}{
+		{name: "no tasks", tasks: nil, want: StatusSkip},
+		{name: "all running", tasks: []client.Task{ready}, want: StatusPass},
+		{name: "missing agent", tasks: []client.Task{ready, missing}, want: StatusFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TaskAgents(tt.tasks); got.Status != tt.want {
+				t.Errorf("TaskAgents() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
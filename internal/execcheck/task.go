@@ -0,0 +1,132 @@
+package execcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/session-manager-plugin/src/version"
+
+	"going/internal/client"
+)
+
+// TaskEnabled checks that the task was started with ExecuteCommand enabled.
+func TaskEnabled(task client.Task) Result {
+	r := Result{Name: "ExecuteCommand is enabled on the task"}
+	if task.ExecuteCommandEnabled {
+		r.Status = StatusPass
+		return r
+	}
+
+	r.Status = StatusFail
+	r.Detail = "The task was started without ExecuteCommand, run `going exec enable` to enable it on the service " +
+		"and replace its tasks."
+	return r
+}
+
+// SSMMessagesEndpoint checks for an ssmmessages VPC endpoint. Without one the
+// task needs outbound internet access to reach Session Manager.
+func SSMMessagesEndpoint(vpcID string, endpoints []string, err error) Result {
+	r := Result{Name: "ssmmessages VPC endpoint"}
+	switch {
+	case err != nil:
+		r.Status = StatusWarn
+		r.Detail = fmt.Sprintf("Couldn't look up the VPC endpoints: %s", err)
+	case vpcID == "":
+		r.Status = StatusSkip
+		r.Detail = "The task doesn't use awsvpc networking."
+	case len(endpoints) == 0:
+		r.Status = StatusWarn
+		r.Detail = fmt.Sprintf("No endpoint in %s, the task needs outbound internet access through a NAT gateway "+
+			"or a public IP.", vpcID)
+	default:
+		r.Status = StatusPass
+		r.Detail = fmt.Sprintf("%s in %s", strings.Join(endpoints, ", "), vpcID)
+	}
+	return r
+}
+
+// ClusterKMS checks that the task role can use the KMS key the cluster encrypts
+// ExecuteCommand sessions with.
+func ClusterKMS(cluster types.Cluster, definition *types.TaskDefinition, simulator PolicySimulator) Result {
+	r := Result{Name: "Task role can use the cluster's ExecuteCommand KMS key"}
+
+	var keyID string
+	if c := cluster.Configuration; c != nil && c.ExecuteCommandConfiguration != nil {
+		keyID = aws.ToString(c.ExecuteCommandConfiguration.KmsKeyId)
+	}
+	if keyID == "" {
+		r.Status = StatusSkip
+		r.Detail = "Sessions aren't encrypted with a KMS key."
+		return r
+	}
+
+	roleARN := aws.ToString(definition.TaskRoleArn)
+	if roleARN == "" {
+		r.Status = StatusFail
+		r.Detail = "The task definition has no task role."
+		return r
+	}
+
+	keyARN := kmsKeyARN(aws.ToString(cluster.ClusterArn), keyID)
+	decisions, err := simulator.SimulatePrincipalPolicy(roleARN, []string{keyARN}, "kms:Decrypt")
+	if err != nil {
+		r.Status = StatusWarn
+		r.Detail = fmt.Sprintf("Couldn't simulate the policies of %s: %s", roleARN, err)
+		return r
+	}
+	if decisions["kms:Decrypt"] != "allowed" {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("%s isn't allowed kms:Decrypt on %s", roleARN, keyARN)
+		return r
+	}
+
+	r.Status = StatusPass
+	return r
+}
+
+// CallerExecuteCommand checks that the caller is allowed to run ecs:ExecuteCommand on the task.
+func CallerExecuteCommand(callerARN string, task client.Task, simulator PolicySimulator) Result {
+	r := Result{Name: "You are allowed ecs:ExecuteCommand"}
+	decisions, err := simulator.SimulatePrincipalPolicy(callerARN, []string{task.ClusterARN, task.ARN}, "ecs:ExecuteCommand")
+	if err != nil {
+		r.Status = StatusWarn
+		r.Detail = fmt.Sprintf("Couldn't simulate the policies of %s: %s", callerARN, err)
+		return r
+	}
+	if decisions["ecs:ExecuteCommand"] != "allowed" {
+		r.Status = StatusFail
+		r.Detail = fmt.Sprintf("%s isn't allowed ecs:ExecuteCommand on task %s", callerARN, task.ID())
+		return r
+	}
+
+	r.Status = StatusPass
+	return r
+}
+
+// PluginVersion reports the session manager plugin version built into going.
+func PluginVersion() Result {
+	return Result{
+		Name:   "Session manager plugin",
+		Status: StatusPass,
+		Detail: fmt.Sprintf("Version %s is built into going, it doesn't need to be installed.", version.Version),
+	}
+}
+
+// kmsKeyARN returns the ARN of a key ID or alias in the cluster's account and region.
+func kmsKeyARN(clusterARN string, keyID string) string {
+	if strings.HasPrefix(keyID, "arn:") {
+		return keyID
+	}
+
+	// arn:partition:ecs:region:account:cluster/name
+	parts := strings.Split(clusterARN, ":")
+	if len(parts) < 5 {
+		return keyID
+	}
+	if !strings.HasPrefix(keyID, "alias/") {
+		keyID = "key/" + keyID
+	}
+	return fmt.Sprintf("arn:%s:kms:%s:%s:%s", parts[1], parts[3], parts[4], keyID)
+}
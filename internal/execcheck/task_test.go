@@ -0,0 +1,143 @@
+package execcheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+func TestSSMMessagesEndpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		vpcID     string
+		endpoints []string
+		err       error
+		want      string
+	}{
+		{name: "lookup failed", vpcID: "vpc-1", err: errors.New("UnauthorizedOperation"), want: StatusWarn},
+		{name: "no awsvpc networking", want: StatusSkip},
+		{name: "no endpoint", vpcID: "vpc-1", want: StatusWarn},
+		{name: "endpoint", vpcID: "vpc-1", endpoints: []string{"vpce-1"}, want: StatusPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SSMMessagesEndpoint(tt.vpcID, tt.endpoints, tt.err); got.Status != tt.want {
+				t.Errorf("SSMMessagesEndpoint() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterKMS(t *testing.T) {
+	clusterARN := aws.String("arn:aws:ecs:eu-west-1:123456789012:cluster/main")
+	encrypted := types.Cluster{
+		ClusterArn: clusterARN,
+		Configuration: &types.ClusterConfiguration{
+			ExecuteCommandConfiguration: &types.ExecuteCommandConfiguration{KmsKeyId: aws.String("1234-abcd")},
+		},
+	}
+	role := &types.TaskDefinition{TaskRoleArn: aws.String("arn:aws:iam::123456789012:role/api")}
+
+	tests := []struct {
+		name       string
+		cluster    types.Cluster
+		definition *types.TaskDefinition
+		simulator  fakeSimulator
+		want       string
+	}{
+		{
+			name:       "not encrypted",
+			cluster:    types.Cluster{ClusterArn: clusterARN},
+			definition: role,
+			want:       StatusSkip,
+		},
+		{
+			name:       "allowed",
+			cluster:    encrypted,
+			definition: role,
+			simulator:  fakeSimulator{decisions: map[string]string{"kms:Decrypt": "allowed"}},
+			want:       StatusPass,
+		},
+		{
+			name:       "denied",
+			cluster:    encrypted,
+			definition: role,
+			simulator:  fakeSimulator{decisions: map[string]string{"kms:Decrypt": "explicitDeny"}},
+			want:       StatusFail,
+		},
+		{
+			name:       "no task role",
+			cluster:    encrypted,
+			definition: &types.TaskDefinition{},
+			want:       StatusFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClusterKMS(tt.cluster, tt.definition, tt.simulator); got.Status != tt.want {
+				t.Errorf("ClusterKMS() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallerExecuteCommand(t *testing.T) {
+	task := client.Task{ARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/abc"}
+	tests := []struct {
+		name      string
+		simulator fakeSimulator
+		want      string
+	}{
+		{
+			name:      "allowed",
+			simulator: fakeSimulator{decisions: map[string]string{"ecs:ExecuteCommand": "allowed"}},
+			want:      StatusPass,
+		},
+		{
+			name:      "denied",
+			simulator: fakeSimulator{decisions: map[string]string{"ecs:ExecuteCommand": "implicitDeny"}},
+			want:      StatusFail,
+		},
+		{
+			name:      "simulation not permitted",
+			simulator: fakeSimulator{err: errors.New("AccessDenied")},
+			want:      StatusWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CallerExecuteCommand("arn:aws:iam::123456789012:role/dev", task, tt.simulator)
+			if got.Status != tt.want {
+				t.Errorf("CallerExecuteCommand() got = %v, want %v", got.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestKMSKeyARN(t *testing.T) {
+	clusterARN := "arn:aws:ecs:eu-west-1:123456789012:cluster/main"
+	tests := []struct {
+		name  string
+		keyID string
+		want  string
+	}{
+		{name: "key id", keyID: "1234-abcd", want: "arn:aws:kms:eu-west-1:123456789012:key/1234-abcd"},
+		{name: "alias", keyID: "alias/exec", want: "arn:aws:kms:eu-west-1:123456789012:alias/exec"},
+		{
+			name:  "arn",
+			keyID: "arn:aws:kms:us-east-1:123456789012:key/1234-abcd",
+			want:  "arn:aws:kms:us-east-1:123456789012:key/1234-abcd",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kmsKeyARN(clusterARN, tt.keyID); got != tt.want {
+				t.Errorf("kmsKeyARN() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package execsession
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/session-manager-plugin/src/datachannel"
+	"github.com/aws/session-manager-plugin/src/log"
+	"github.com/aws/session-manager-plugin/src/sessionmanagerplugin/session"
+
+	// import for side effect of registering the shell session
+	_ "github.com/aws/session-manager-plugin/src/sessionmanagerplugin/session/shellsession"
+	"github.com/google/uuid"
+
+	"going/internal/client"
+	"going/internal/factory"
+)
+
+// Exec runs the command interactively in the container with ECS ExecuteCommand.
+// The session manager plugin exits the process when the session ends so Exec
+// only returns when the session couldn't be started. Started, if not nil, is
+// called once the session is created and before attaching to it.
+func Exec(f *factory.Factory, c *client.AWSClient, target client.Container, command string, started func()) error {
+	ssmTarget, err := target.SSMTarget()
+	if err != nil {
+		return err
+	}
+
+	out, err := c.ExecuteCommand(&ecs.ExecuteCommandInput{
+		Cluster:     aws.String(target.ClusterARN),
+		Container:   aws.String(target.Name),
+		Task:        aws.String(target.TaskARN),
+		Command:     aws.String(command),
+		Interactive: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	return execute(f, ssmTarget, out.Session.SessionId, out.Session.StreamUrl, out.Session.TokenValue, started)
+}
+
+// StartSSM starts an SSM session with the container directly, giving a basic
+// sh shell. Like Exec it only returns when the session couldn't be started.
+func StartSSM(f *factory.Factory, target client.Container, started func()) error {
+	ssmTarget, err := target.SSMTarget()
+	if err != nil {
+		return err
+	}
+
+	ssmClient := ssm.NewFromConfig(f.Config())
+	out, err := ssmClient.StartSession(f.Context, &ssm.StartSessionInput{Target: aws.String(ssmTarget)})
+	if err != nil {
+		return err
+	}
+
+	return execute(f, ssmTarget, out.SessionId, out.StreamUrl, out.TokenValue, started)
+}
+
+func execute(f *factory.Factory, target string, sessionID *string, streamURL *string, token *string, started func()) error {
+	ep, err := ssm.NewDefaultEndpointResolver().ResolveEndpoint(f.Config().Region, ssm.EndpointResolverOptions{})
+	if err != nil {
+		return err
+	}
+
+	if started != nil {
+		started()
+	}
+
+	ssmSession := session.Session{
+		SessionId:   aws.ToString(sessionID),
+		StreamUrl:   aws.ToString(streamURL),
+		TokenValue:  aws.ToString(token),
+		Endpoint:    ep.URL,
+		ClientId:    uuid.NewString(),
+		TargetId:    target,
+		DataChannel: &datachannel.DataChannel{},
+	}
+
+	return ssmSession.Execute(log.Logger(false, ssmSession.ClientId))
+}
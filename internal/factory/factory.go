@@ -2,20 +2,35 @@ package factory
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 
 	"going/internal/awsconfig"
+	"going/internal/goingconfig"
 	"going/internal/utils"
 )
 
+// AliasAnnotation marks a command as accepting an alias from the going config
+// as its first positional argument.
+const AliasAnnotation = "going/alias"
+
 type Factory struct {
 	Prompt         utils.Prompt
 	LocalAWSConfig awsconfig.Config
+	GoingConfig    goingconfig.Config
 	Context        context.Context
 	ProfileName    string
+	Region         string
+	AliasName      string
+	NonInteractive bool
+	AssumeYes      bool
+	// Overrides are settings chosen at runtime, e.g. a target from the history,
+	// that take precedence over the going config.
+	Overrides goingconfig.Settings
 
 	config          aws.Config
 	selectedProfile awsconfig.Profile
@@ -24,9 +39,12 @@ type Factory struct {
 func New() *Factory {
 	awsCfg, err := awsconfig.Read(&awsconfig.ConfigFileLoader{}, awsconfig.Filename())
 	utils.CheckErr(err)
+	goingCfg, err := goingconfig.Read(goingconfig.Filename())
+	utils.CheckErr(err)
 	f := &Factory{
 		Prompt:         utils.Prompter{},
 		LocalAWSConfig: awsCfg,
+		GoingConfig:    goingCfg,
 	}
 	return f
 }
@@ -36,9 +54,19 @@ func (f *Factory) Config() aws.Config {
 		return f.config
 	}
 
-	cfg, err := config.LoadDefaultConfig(f.Context,
+	f.selectProfile()
+	if f.Region == "" {
+		f.Region = f.Settings().Region
+	}
+
+	optFns := []func(*config.LoadOptions) error{
 		config.WithSharedConfigProfile(f.ProfileName),
-	)
+	}
+	if f.Region != "" {
+		optFns = append(optFns, config.WithRegion(f.Region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(f.Context, optFns...)
 	utils.CheckErr(err)
 	f.config = cfg
 	return cfg
@@ -48,8 +76,64 @@ func (f *Factory) SelectedProfile() awsconfig.Profile {
 	if f.selectedProfile != (awsconfig.Profile{}) {
 		return f.selectedProfile
 	}
+	f.selectProfile()
 	profile, err := f.LocalAWSConfig.GetProfile(f.ProfileName)
 	utils.CheckErr(err)
 	f.selectedProfile = profile
 	return profile
 }
+
+// Settings returns the going config settings for the selected profile and
+// alias with the Overrides applied on top.
+func (f *Factory) Settings() goingconfig.Settings {
+	profile := f.ProfileName
+	if profile == "" {
+		profile = f.Overrides.Profile
+	}
+
+	s := f.GoingConfig.Resolve(profile, f.AliasName).Merge(f.Overrides)
+	if profile != "" {
+		s.Profile = profile
+	}
+	return s
+}
+
+// UseAlias selects the alias with the given name from the going config.
+func (f *Factory) UseAlias(name string) error {
+	if _, ok := f.GoingConfig.Alias(name); !ok {
+		return fmt.Errorf("no alias named '%s' in %s", name, goingconfig.Filename())
+	}
+	f.AliasName = name
+	return nil
+}
+
+// selectProfile uses the profile from the settings when the profile flag isn't
+// given, and prompts for one as a last resort.
+func (f *Factory) selectProfile() {
+	if f.ProfileName == "" {
+		f.ProfileName = f.Settings().Profile
+	}
+	if f.ProfileName == "" && f.NonInteractive {
+		utils.CheckErr(fmt.Errorf("no profile given and running non-interactively, use --profile with one of: %s",
+			strings.Join(f.LocalAWSConfig.ProfileNames(), ", ")))
+	}
+	if f.ProfileName == "" {
+		var err error
+		f.ProfileName, err = f.Prompt.Select("Select a profile", f.LocalAWSConfig.ProfileNames())
+		utils.CheckErr(err)
+	}
+}
+
+// ConfigurePrompt picks how to prompt the user. Going runs non-interactively
+// when asked to or when stdin isn't a terminal.
+func (f *Factory) ConfigurePrompt() {
+	if !utils.StdinIsTerminal() {
+		f.NonInteractive = true
+	}
+
+	if f.NonInteractive {
+		f.Prompt = utils.NonInteractivePrompter{AssumeYes: f.AssumeYes}
+	} else {
+		f.Prompt = utils.Prompter{AssumeYes: f.AssumeYes}
+	}
+}
@@ -0,0 +1,132 @@
+package goingconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"going/internal/utils"
+)
+
+// Settings are the values that can be set as defaults, per profile, or in an alias.
+type Settings struct {
+	Profile    string `yaml:"profile"`
+	Region     string `yaml:"region"`
+	Cluster    string `yaml:"cluster"`
+	Service    string `yaml:"service"`
+	Container  string `yaml:"container"`
+	Shell      string `yaml:"shell"`
+	LogMinutes int    `yaml:"log_minutes"`
+	DebugImage string `yaml:"debug_image"`
+	LogRender  string `yaml:"log_render"`
+}
+
+// Config is going's own configuration file.
+type Config struct {
+	Defaults Settings            `yaml:"defaults"`
+	Profiles map[string]Settings `yaml:"profiles"`
+	Aliases  map[string]Settings `yaml:"aliases"`
+	// Queries are saved Logs Insights queries by name.
+	Queries map[string]string `yaml:"queries"`
+}
+
+// Parse decodes the YAML config.
+func Parse(b []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse going config, %w", err)
+	}
+	return cfg, nil
+}
+
+// Read loads the config from filename. A missing file is not an error, it
+// just results in an empty config.
+func Read(filename string) (Config, error) {
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read going config, %w", err)
+	}
+
+	return Parse(b)
+}
+
+// Alias returns the settings for the alias with the given name.
+func (c *Config) Alias(name string) (Settings, bool) {
+	s, ok := c.Aliases[name]
+	return s, ok
+}
+
+// Resolve merges the defaults, the settings for the profile, and the alias
+// settings in that order. Later values override earlier ones.
+func (c *Config) Resolve(profile string, alias string) Settings {
+	s := c.Defaults
+
+	if a, ok := c.Alias(alias); ok {
+		// The profile from the alias has to be known before the profile settings are merged.
+		if profile == "" && a.Profile != "" {
+			profile = a.Profile
+		}
+	}
+	if profile == "" {
+		profile = s.Profile
+	}
+
+	if p, ok := c.Profiles[profile]; ok {
+		s = s.Merge(p)
+	}
+	if a, ok := c.Alias(alias); ok {
+		s = s.Merge(a)
+	}
+
+	s.Profile = profile
+	return s
+}
+
+// Merge returns a copy of s with any non-zero values of o replacing its values.
+func (s Settings) Merge(o Settings) Settings {
+	if o.Profile != "" {
+		s.Profile = o.Profile
+	}
+	if o.Region != "" {
+		s.Region = o.Region
+	}
+	if o.Cluster != "" {
+		s.Cluster = o.Cluster
+	}
+	if o.Service != "" {
+		s.Service = o.Service
+	}
+	if o.Container != "" {
+		s.Container = o.Container
+	}
+	if o.Shell != "" {
+		s.Shell = o.Shell
+	}
+	if o.LogMinutes != 0 {
+		s.LogMinutes = o.LogMinutes
+	}
+	if o.DebugImage != "" {
+		s.DebugImage = o.DebugImage
+	}
+	if o.LogRender != "" {
+		s.LogRender = o.LogRender
+	}
+	return s
+}
+
+// Dir returns the directory going stores its config and state in.
+func Dir() string {
+	return filepath.Join(utils.UserHomeDir(), ".config", "going")
+}
+
+// Filename returns the path to going's config file.
+func Filename() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
@@ -0,0 +1,173 @@
+package goingconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `defaults:
+  profile: staging
+  region: us-east-1
+  shell: /bin/sh
+  log_minutes: 15
+profiles:
+  prod:
+    region: eu-west-1
+    cluster: main
+aliases:
+  api-prod:
+    profile: prod
+    service: api
+    container: web
+  worker:
+    service: worker
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    Config
+		wantErr bool
+	}{
+		{
+			name:   "empty config",
+			config: "",
+			want:   Config{},
+		},
+		{
+			name:   "defaults only",
+			config: "defaults:\n  cluster: main\n  log_minutes: 5\n  debug_image: busybox\n  log_render: flat\n",
+			want:   Config{Defaults: Settings{Cluster: "main", LogMinutes: 5, DebugImage: "busybox", LogRender: "flat"}},
+		},
+		{
+			name:   "saved queries",
+			config: "queries:\n  errors: |\n    filter level = \"error\"\n    | stats count(*) by path\n",
+			want: Config{Queries: map[string]string{
+				"errors": "filter level = \"error\"\n| stats count(*) by path\n",
+			}},
+		},
+		{
+			name:    "invalid yaml",
+			config:  "defaults: [",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.config))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(filename, []byte(testConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		aliases  int
+		wantErr  bool
+	}{
+		{
+			name:     "reads the file",
+			filename: filename,
+			aliases:  2,
+		},
+		{
+			name:     "missing file is empty config",
+			filename: filepath.Join(dir, "missing.yaml"),
+			aliases:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Read() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got.Aliases) != tt.aliases {
+				t.Errorf("Read() got %d aliases, want %d", len(got.Aliases), tt.aliases)
+			}
+		})
+	}
+}
+
+func TestConfig_Resolve(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		profile string
+		alias   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want Settings
+	}{
+		{
+			name: "defaults",
+			args: args{},
+			want: Settings{Profile: "staging", Region: "us-east-1", Shell: "/bin/sh", LogMinutes: 15},
+		},
+		{
+			name: "profile settings override defaults",
+			args: args{profile: "prod"},
+			want: Settings{Profile: "prod", Region: "eu-west-1", Cluster: "main", Shell: "/bin/sh", LogMinutes: 15},
+		},
+		{
+			name: "alias selects the profile",
+			args: args{alias: "api-prod"},
+			want: Settings{
+				Profile:    "prod",
+				Region:     "eu-west-1",
+				Cluster:    "main",
+				Service:    "api",
+				Container:  "web",
+				Shell:      "/bin/sh",
+				LogMinutes: 15,
+			},
+		},
+		{
+			name: "profile argument overrides the alias profile",
+			args: args{profile: "staging", alias: "api-prod"},
+			want: Settings{
+				Profile:    "staging",
+				Region:     "us-east-1",
+				Service:    "api",
+				Container:  "web",
+				Shell:      "/bin/sh",
+				LogMinutes: 15,
+			},
+		},
+		{
+			name: "alias without profile uses the default profile",
+			args: args{alias: "worker"},
+			want: Settings{Profile: "staging", Region: "us-east-1", Service: "worker", Shell: "/bin/sh", LogMinutes: 15},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Resolve(tt.args.profile, tt.args.alias); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
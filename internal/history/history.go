@@ -0,0 +1,174 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/goingconfig"
+	"going/internal/utils"
+)
+
+// maxEntries is the number of targets kept in the history file.
+const maxEntries = 50
+
+// Entry is a target that a command successfully connected to.
+type Entry struct {
+	Command   string    `json:"command"`
+	Profile   string    `json:"profile"`
+	Region    string    `json:"region"`
+	Cluster   string    `json:"cluster"`
+	Service   string    `json:"service"`
+	Container string    `json:"container"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// History the recently used targets, the most recent first.
+type History struct {
+	Entries []Entry `json:"entries"`
+
+	filename string
+}
+
+// Settings returns the entry as settings that can override the going config.
+func (e Entry) Settings() goingconfig.Settings {
+	return goingconfig.Settings{
+		Profile:   e.Profile,
+		Region:    e.Region,
+		Cluster:   e.Cluster,
+		Service:   e.Service,
+		Container: e.Container,
+	}
+}
+
+// Ago returns how long ago the entry was used in a human friendly format.
+func (e Entry) Ago() string {
+	d := time.Since(e.Timestamp)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// String returns the target in the form profile:cluster/service/container.
+func (e Entry) String() string {
+	return fmt.Sprintf("%s:%s/%s/%s", e.Profile, e.Cluster, e.Service, e.Container)
+}
+
+func (e Entry) sameTarget(o Entry) bool {
+	return e.Profile == o.Profile && e.Region == o.Region && e.Cluster == o.Cluster &&
+		e.Service == o.Service && e.Container == o.Container
+}
+
+// Add puts the entry at the front of the history, removing any older entry for the same target.
+func (h *History) Add(e Entry) {
+	entries := []Entry{e}
+	for _, existing := range h.Entries {
+		if existing.sameTarget(e) {
+			continue
+		}
+		entries = append(entries, existing)
+	}
+
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+	h.Entries = entries
+}
+
+// Last returns the most recent entry.
+func (h *History) Last() (Entry, error) {
+	if len(h.Entries) == 0 {
+		return Entry{}, fmt.Errorf("no recent targets in %s", h.filename)
+	}
+	return h.Entries[0], nil
+}
+
+// Write stores the history in its file.
+func (h *History) Write() error {
+	if err := os.MkdirAll(filepath.Dir(h.filename), 0700); err != nil {
+		return err
+	}
+	return utils.StoreCacheFile(h.filename, h, 0600)
+}
+
+// Read loads the history from filename. A missing file is an empty history.
+func Read(filename string) (History, error) {
+	h := History{filename: filename}
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read history file, %w", err)
+	}
+
+	if err := json.Unmarshal(b, &h); err != nil {
+		return h, fmt.Errorf("failed to parse history file, %w", err)
+	}
+
+	return h, nil
+}
+
+// Record adds the entry to the history file.
+func Record(e Entry) error {
+	h, err := Read(Filename())
+	if err != nil {
+		return err
+	}
+
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	h.Add(e)
+	return h.Write()
+}
+
+// UseLast makes the most recent target in the history file override the
+// settings of the command.
+func UseLast(f *factory.Factory) error {
+	h, err := Read(Filename())
+	if err != nil {
+		return err
+	}
+	e, err := h.Last()
+	if err != nil {
+		return err
+	}
+	f.Overrides = e.Settings()
+	return nil
+}
+
+// RecordTarget adds the container the command connected to to the history
+// file. Failing to write the history shouldn't stop the command so only a
+// warning is printed.
+func RecordTarget(f *factory.Factory, command string, target client.Container) {
+	err := Record(Entry{
+		Command:   command,
+		Profile:   f.ProfileName,
+		Region:    f.Config().Region,
+		Cluster:   target.ClusterName,
+		Service:   target.ServiceName,
+		Container: target.Name,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Warning: failed to record target in history:", err)
+	}
+}
+
+// Filename returns the path to the history file.
+func Filename() string {
+	return filepath.Join(goingconfig.Dir(), "history.json")
+}
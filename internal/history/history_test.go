@@ -0,0 +1,90 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHistory_Add(t *testing.T) {
+	api := Entry{Command: "shell", Profile: "prod", Cluster: "main", Service: "api", Container: "web"}
+	worker := Entry{Command: "logs", Profile: "prod", Cluster: "main", Service: "worker", Container: "app"}
+	apiLogs := Entry{Command: "logs", Profile: "prod", Cluster: "main", Service: "api", Container: "web"}
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		add     Entry
+		want    []Entry
+	}{
+		{
+			name:    "empty history",
+			entries: nil,
+			add:     api,
+			want:    []Entry{api},
+		},
+		{
+			name:    "newest first",
+			entries: []Entry{api},
+			add:     worker,
+			want:    []Entry{worker, api},
+		},
+		{
+			name:    "same target moves to the front",
+			entries: []Entry{worker, api},
+			add:     apiLogs,
+			want:    []Entry{apiLogs, worker},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &History{Entries: tt.entries}
+			h.Add(tt.add)
+			if !reflect.DeepEqual(h.Entries, tt.want) {
+				t.Errorf("Add() got = %+v, want %+v", h.Entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory_AddLimitsEntries(t *testing.T) {
+	h := &History{}
+	for i := 0; i < maxEntries+5; i++ {
+		h.Add(Entry{Service: "service", Container: string(rune('a' + i))})
+	}
+
+	if len(h.Entries) != maxEntries {
+		t.Errorf("Add() got %d entries, want %d", len(h.Entries), maxEntries)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "going", "history.json")
+
+	h, err := Read(filename)
+	if err != nil {
+		t.Fatalf("Read() of missing file error = %v", err)
+	}
+	if _, err := h.Last(); err == nil {
+		t.Errorf("Last() of empty history should return an error")
+	}
+
+	e := Entry{Command: "shell", Profile: "prod", Service: "api", Timestamp: time.Now().Round(time.Second)}
+	h.Add(e)
+	if err := h.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	h, err = Read(filename)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	last, err := h.Last()
+	if err != nil {
+		t.Fatalf("Last() error = %v", err)
+	}
+	if !last.Timestamp.Equal(e.Timestamp) || last.Service != e.Service {
+		t.Errorf("Last() got = %+v, want %+v", last, e)
+	}
+}
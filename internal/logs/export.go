@@ -0,0 +1,296 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/smithy-go"
+
+	"going/internal/client"
+)
+
+// The formats events can be exported in.
+const (
+	// ExportNDJSON writes an event as a JSON object per line.
+	ExportNDJSON = "ndjson"
+	// ExportText writes events the way the logs command prints them.
+	ExportText = "text"
+)
+
+// ExportFormats are the formats an Export accepts.
+var ExportFormats = []string{ExportNDJSON, ExportText}
+
+// The backoff when FilterLogEvents is throttled.
+const (
+	throttleRetries  = 8
+	throttleDelay    = time.Second
+	maxThrottleDelay = 30 * time.Second
+)
+
+// PageReader reads the events matching a query a page at a time.
+type PageReader interface {
+	FilterLogEventsPage(query client.LogQuery, nextToken string) ([]client.LogEvent, string, error)
+}
+
+// Export writes the events matching a query to a file. The progress is saved
+// to a state file next to it after each page, so an interrupted export of
+// the same query resumes where it stopped. FilterLogEvents page tokens
+// expire after 24 hours.
+type Export struct {
+	Client PageReader
+	Query  client.LogQuery
+	// Window is the time range as given, like "1h" to now. It identifies the
+	// export instead of the query's times, which relative ranges move on
+	// each run. A resumed export reads the times it started with.
+	Window   string
+	Filename string
+	Format   string
+	// Gzip compresses the file. Each page is its own gzip member so a
+	// resumed export is still a valid gzip file.
+	Gzip bool
+	// Restart ignores the progress of an earlier export.
+	Restart bool
+	// Progress is written a line per page, nil to not report progress.
+	Progress io.Writer
+
+	sleep func(time.Duration)
+}
+
+// exportState is the progress of an export.
+type exportState struct {
+	// Key identifies the query and format, an export only resumes the same one.
+	Key       string    `json:"key"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	NextToken string    `json:"nextToken"`
+	Offset    int64     `json:"offset"`
+	Events    int       `json:"events"`
+}
+
+// exportEvent is an event as written to NDJSON files.
+type exportEvent struct {
+	Timestamp     time.Time `json:"timestamp"`
+	IngestionTime time.Time `json:"ingestionTime"`
+	Group         string    `json:"group"`
+	Stream        string    `json:"stream"`
+	ID            string    `json:"id"`
+	Message       string    `json:"message"`
+}
+
+// StateFilename returns the file the progress of an export to filename is saved in.
+func StateFilename(filename string) string {
+	return filename + ".export-state"
+}
+
+// Run exports the events and returns how many were written in total,
+// including those written before resuming.
+func (e *Export) Run() (int, error) {
+	if e.Format != ExportNDJSON && e.Format != ExportText {
+		return 0, fmt.Errorf("invalid export format '%s', use one of %s", e.Format, strings.Join(ExportFormats, ", "))
+	}
+
+	state := exportState{Key: e.key(), StartTime: e.Query.StartTime, EndTime: e.Query.EndTime}
+	resumed := false
+	if !e.Restart {
+		saved, err := readExportState(StateFilename(e.Filename))
+		if err != nil {
+			return 0, err
+		}
+		if saved.Key == state.Key {
+			state = saved
+			resumed = true
+			e.Query.StartTime, e.Query.EndTime = state.StartTime, state.EndTime
+			e.progressf("Resuming after %d events from %s to %s", state.Events,
+				state.StartTime.Format(time.RFC3339), state.EndTime.Format(time.RFC3339))
+		}
+	}
+	if !resumed {
+		if info, err := os.Stat(e.Filename); err == nil && info.Size() > 0 {
+			e.progressf("Overwriting %s, there is no progress of the same export to resume", e.Filename)
+		}
+	}
+
+	f, err := os.OpenFile(e.Filename, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return state.Events, err
+	}
+	defer f.Close()
+
+	// Anything written after the last saved page is written again.
+	if err := f.Truncate(state.Offset); err != nil {
+		return state.Events, err
+	}
+	if _, err := f.Seek(state.Offset, io.SeekStart); err != nil {
+		return state.Events, err
+	}
+
+	for {
+		events, next, err := e.readPage(state.NextToken)
+		if err != nil {
+			return state.Events, err
+		}
+
+		b, err := e.encode(events)
+		if err != nil {
+			return state.Events, err
+		}
+		n, err := f.Write(b)
+		if err != nil {
+			return state.Events, err
+		}
+
+		state.Offset += int64(n)
+		state.Events += len(events)
+		state.NextToken = next
+		if next == "" {
+			// A missing state file means the export finished.
+			err = os.Remove(StateFilename(e.Filename))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return state.Events, err
+			}
+			return state.Events, nil
+		}
+
+		if err := writeExportState(StateFilename(e.Filename), state); err != nil {
+			return state.Events, err
+		}
+		if len(events) > 0 {
+			e.progressf("%d events, up to %s", state.Events, events[len(events)-1].Timestamp.Format(time.RFC3339))
+		}
+	}
+}
+
+// readPage reads a page, backing off while FilterLogEvents is throttled.
+func (e *Export) readPage(nextToken string) ([]client.LogEvent, string, error) {
+	sleep := e.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	delay := throttleDelay
+	for retry := 0; ; retry++ {
+		events, next, err := e.Client.FilterLogEventsPage(e.Query, nextToken)
+		if err == nil || !isThrottled(err) || retry == throttleRetries {
+			return events, next, err
+		}
+
+		e.progressf("Throttled, retrying in %s", delay)
+		sleep(delay)
+		delay *= 2
+		if delay > maxThrottleDelay {
+			delay = maxThrottleDelay
+		}
+	}
+}
+
+// encode formats the events of a page.
+func (e *Export) encode(events []client.LogEvent) ([]byte, error) {
+	var b bytes.Buffer
+	var w io.Writer = &b
+	var gz *gzip.Writer
+	if e.Gzip {
+		gz = gzip.NewWriter(&b)
+		w = gz
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, event := range events {
+		var err error
+		if e.Format == ExportNDJSON {
+			err = enc.Encode(exportEvent{
+				Timestamp:     event.Timestamp.UTC(),
+				IngestionTime: event.IngestionTime.UTC(),
+				Group:         event.GroupName,
+				Stream:        event.StreamName,
+				ID:            event.ID,
+				Message:       event.Message,
+			})
+		} else {
+			_, err = fmt.Fprintf(w, "%s [%s] %s\n",
+				event.StreamName, event.Timestamp.UTC().Format(time.RFC3339Nano), strings.TrimSuffix(event.Message, "\n"))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if gz != nil {
+		// Empty pages aren't written so there are no empty gzip members.
+		if len(events) == 0 {
+			return nil, nil
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func (e *Export) key() string {
+	q := e.Query
+	window := e.Window
+	if window == "" {
+		window = fmt.Sprintf("%d-%d", q.StartTime.UnixMilli(), q.EndTime.UnixMilli())
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%t", q.GroupName, q.StreamPrefix, strings.Join(q.StreamNames, ","),
+		window, q.FilterPattern, e.Format, e.Gzip)
+}
+
+func (e *Export) progressf(format string, a ...interface{}) {
+	if e.Progress != nil {
+		_, _ = fmt.Fprintf(e.Progress, format+"\n", a...)
+	}
+}
+
+// isThrottled checks if the request was throttled.
+func isThrottled(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "LimitExceededException", "ServiceUnavailableException":
+		return true
+	}
+	return false
+}
+
+// readExportState reads the saved progress, empty if there is none.
+func readExportState(filename string) (exportState, error) {
+	var state exportState
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read export state, %w", err)
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return state, fmt.Errorf("failed to parse export state %s, %w", filename, err)
+	}
+	return state, nil
+}
+
+// writeExportState saves the progress, replacing the file so it's never
+// left half written.
+func writeExportState(filename string, state exportState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write export state, %w", err)
+	}
+	return os.Rename(tmp, filename)
+}
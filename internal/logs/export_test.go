@@ -0,0 +1,201 @@
+package logs
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+
+	"going/internal/client"
+)
+
+// fakePages returns pages of one event each, failing at the pages in errs.
+type fakePages struct {
+	pages   int
+	errs    map[int]error
+	calls   int
+	queries []client.LogQuery
+}
+
+func (p *fakePages) FilterLogEventsPage(query client.LogQuery, nextToken string) ([]client.LogEvent, string, error) {
+	p.calls++
+	p.queries = append(p.queries, query)
+	page := 0
+	if nextToken != "" {
+		page, _ = strconv.Atoi(nextToken)
+	}
+	if err, ok := p.errs[page]; ok {
+		delete(p.errs, page)
+		return nil, "", err
+	}
+
+	event := client.LogEvent{
+		ID:         strconv.Itoa(page),
+		GroupName:  query.GroupName,
+		StreamName: "ecs/web/aaa",
+		Timestamp:  time.Date(2024, 3, 10, 9, 0, page, 0, time.UTC),
+		Message:    "event " + strconv.Itoa(page),
+	}
+	next := ""
+	if page+1 < p.pages {
+		next = strconv.Itoa(page + 1)
+	}
+	return []client.LogEvent{event}, next, nil
+}
+
+func TestExport(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException"}
+	want := "ecs/web/aaa [2024-03-10T09:00:00Z] event 0\n" +
+		"ecs/web/aaa [2024-03-10T09:00:01Z] event 1\n" +
+		"ecs/web/aaa [2024-03-10T09:00:02Z] event 2\n"
+
+	tests := []struct {
+		name     string
+		gzip     bool
+		errs     map[int]error
+		wantRuns int
+	}{
+		{name: "text", errs: map[int]error{}, wantRuns: 1},
+		{name: "throttled", errs: map[int]error{1: throttled}, wantRuns: 1},
+		{name: "resumed", errs: map[int]error{2: errors.New("connection reset")}, wantRuns: 2},
+		{name: "resumed gzip", gzip: true, errs: map[int]error{1: errors.New("connection reset")}, wantRuns: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "api.log")
+			pages := &fakePages{pages: 3, errs: tt.errs}
+			e := &Export{
+				Client:   pages,
+				Query:    client.LogQuery{GroupName: "/ecs/api", StreamPrefix: "ecs/web/"},
+				Filename: filename,
+				Format:   ExportText,
+				Gzip:     tt.gzip,
+				sleep:    func(time.Duration) {},
+			}
+
+			var n, runs int
+			var err error
+			for runs < 3 {
+				runs++
+				if n, err = e.Run(); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("Run() took %d runs, want %d", runs, tt.wantRuns)
+			}
+			if n != 3 {
+				t.Errorf("Run() = %d events, want 3", n)
+			}
+
+			f, err := os.Open(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var r io.Reader = f
+			if tt.gzip {
+				if r, err = gzip.NewReader(f); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("exported %q, want %q", got, want)
+			}
+
+			if _, err := os.Stat(StateFilename(filename)); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("state file should be removed after the export finishes, got %v", err)
+			}
+		})
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api.ndjson")
+	e := &Export{
+		Client:   &fakePages{pages: 1},
+		Query:    client.LogQuery{GroupName: "/ecs/api"},
+		Filename: filename,
+		Format:   ExportNDJSON,
+	}
+	if _, err := e.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"timestamp":"2024-03-10T09:00:00Z","ingestionTime":"0001-01-01T00:00:00Z","group":"/ecs/api",` +
+		`"stream":"ecs/web/aaa","id":"0","message":"event 0"}` + "\n"
+	if string(got) != want {
+		t.Errorf("exported %s, want %s", got, want)
+	}
+}
+
+func TestExportResumesRelativeWindow(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api.log")
+	pages := &fakePages{pages: 3, errs: map[int]error{1: errors.New("connection reset")}}
+	first := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	e := &Export{
+		Client:   pages,
+		Query:    client.LogQuery{GroupName: "/ecs/api", StartTime: first.Add(-time.Hour), EndTime: first},
+		Window:   "1h to now",
+		Filename: filename,
+		Format:   ExportText,
+	}
+	if _, err := e.Run(); err == nil {
+		t.Fatal("first Run() should fail")
+	}
+
+	// Running the same command later resolves a later window.
+	e.Query.StartTime, e.Query.EndTime = first.Add(10*time.Minute-time.Hour), first.Add(10*time.Minute)
+	n, err := e.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("Run() = %d events, want 3", n)
+	}
+	for _, q := range pages.queries {
+		if !q.EndTime.Equal(first) {
+			t.Errorf("resumed query ends at %s, want the first window's end %s", q.EndTime, first)
+		}
+	}
+}
+
+func TestExportWarnsBeforeOverwriting(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "api.log")
+	if err := os.WriteFile(filename, []byte("earlier export\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var progress strings.Builder
+	e := &Export{
+		Client:   &fakePages{pages: 1},
+		Query:    client.LogQuery{GroupName: "/ecs/api"},
+		Filename: filename,
+		Format:   ExportText,
+		Progress: &progress,
+	}
+	if _, err := e.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(progress.String(), "Overwriting "+filename) {
+		t.Errorf("progress = %q, want a warning before overwriting", progress.String())
+	}
+}
@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// JSONFormat decodes messages that are JSON objects.
+type JSONFormat struct{}
+
+// Name returns json.
+func (JSONFormat) Name() string {
+	return "json"
+}
+
+// Decode decodes the message if it's a JSON object. Numbers are kept as
+// json.Number so they're printed as they were logged.
+func (JSONFormat) Decode(message string) (map[string]interface{}, bool) {
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "{") {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(message))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil || dec.More() {
+		return nil, false
+	}
+	return fields, true
+}
+
+// LogfmtFormat decodes messages in logfmt, key=value pairs separated by
+// spaces where values with spaces are quoted.
+type LogfmtFormat struct{}
+
+// Name returns logfmt.
+func (LogfmtFormat) Name() string {
+	return "logfmt"
+}
+
+// Decode decodes the message if every part of it is a key=value pair, so
+// plain text isn't mistaken for bare logfmt keys.
+func (LogfmtFormat) Decode(message string) (map[string]interface{}, bool) {
+	fields := map[string]interface{}{}
+	s := strings.TrimSpace(message)
+	for s != "" {
+		eq := strings.IndexAny(s, "= ")
+		if eq <= 0 || s[eq] != '=' {
+			return nil, false
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, false
+			}
+			value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+			if s != "" && s[0] != ' ' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+
+		fields[key] = value
+		s = strings.TrimLeft(s, " ")
+	}
+
+	if len(fields) == 0 {
+		return nil, false
+	}
+	return fields, true
+}
@@ -0,0 +1,61 @@
+package logs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/manifoldco/promptui"
+)
+
+// highlight colors the parts of a message matching the grep expression.
+var highlight = promptui.Styler(promptui.FGRed, promptui.FGBold)
+
+// Grep selects log messages with a regular expression on the client side,
+// after CloudWatch has applied any filter pattern.
+type Grep struct {
+	re *regexp.Regexp
+	// Invert selects the messages that don't match instead.
+	Invert bool
+	// Color highlights the matches in the selected messages.
+	Color bool
+}
+
+// NewGrep compiles the expression.
+func NewGrep(expr string, invert bool, color bool) (*Grep, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --grep expression, %w", err)
+	}
+	return &Grep{re: re, Invert: invert, Color: color}, nil
+}
+
+// Apply checks if the message is selected and returns it with the matches
+// highlighted. A nil Grep selects every message unchanged.
+func (g *Grep) Apply(message string) (string, bool) {
+	if g == nil {
+		return message, true
+	}
+
+	matches := g.re.FindAllStringIndex(message, -1)
+	if g.Invert {
+		return message, len(matches) == 0
+	}
+	if len(matches) == 0 {
+		return message, false
+	}
+	if !g.Color {
+		return message, true
+	}
+
+	var result string
+	last := 0
+	for _, m := range matches {
+		// Empty matches have nothing to highlight.
+		if m[0] == m[1] {
+			continue
+		}
+		result += message[last:m[0]] + highlight(message[m[0]:m[1]])
+		last = m[1]
+	}
+	return result + message[last:], true
+}
@@ -0,0 +1,50 @@
+package logs
+
+import "testing"
+
+func TestGrep(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		invert      bool
+		color       bool
+		message     string
+		want        string
+		wantMatched bool
+	}{
+		{name: "match", expr: "error", message: "an error occurred", want: "an error occurred", wantMatched: true},
+		{name: "no match", expr: "error", message: "all good", want: "all good"},
+		{name: "invert match", expr: "health", invert: true, message: "GET /health 200", want: "GET /health 200"},
+		{name: "invert no match", expr: "health", invert: true, message: "GET /users 500", want: "GET /users 500", wantMatched: true},
+		{
+			name:        "highlight",
+			expr:        "5[0-9]{2}",
+			color:       true,
+			message:     "GET /users 500 in 503ms",
+			want:        "GET /users " + highlight("500") + " in " + highlight("503") + "ms",
+			wantMatched: true,
+		},
+		{name: "empty match", expr: "x*", color: true, message: "abc", want: "abc", wantMatched: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGrep(tt.expr, tt.invert, tt.color)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, matched := g.Apply(tt.message)
+			if got != tt.want || matched != tt.wantMatched {
+				t.Errorf("Apply() = %q, %v, want %q, %v", got, matched, tt.want, tt.wantMatched)
+			}
+		})
+	}
+
+	if _, err := NewGrep("(", false, false); err == nil {
+		t.Error("NewGrep() expected an error for an invalid expression")
+	}
+
+	var g *Grep
+	if got, matched := g.Apply("anything"); got != "anything" || !matched {
+		t.Errorf("nil Apply() = %q, %v, want the message selected", got, matched)
+	}
+}
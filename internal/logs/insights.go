@@ -0,0 +1,87 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
+	"going/internal/client"
+	"going/internal/output"
+)
+
+var (
+	// ErrQueryTimeout is returned when a query doesn't complete in time.
+	ErrQueryTimeout = errors.New("timed out waiting for the query")
+	// ErrQueryInterrupted is returned when waiting for a query is interrupted.
+	ErrQueryInterrupted = errors.New("interrupted waiting for the query")
+)
+
+// QueryClient runs Logs Insights queries.
+type QueryClient interface {
+	StartQuery(query client.InsightsQuery) (string, error)
+	GetQueryResults(queryID string) (client.InsightsResults, error)
+	StopQuery(queryID string) error
+}
+
+// QueryRunner starts a Logs Insights query and polls for its results.
+type QueryRunner struct {
+	Client   QueryClient
+	Interval time.Duration
+	Timeout  time.Duration
+	// Interrupt stops the query when it receives a signal, otherwise the
+	// query keeps running in CloudWatch after going exits.
+	Interrupt <-chan os.Signal
+}
+
+// Run starts the query and returns its results once it completes. The query
+// is stopped if it doesn't complete within the timeout or is interrupted.
+func (r *QueryRunner) Run(query client.InsightsQuery) (client.InsightsResults, error) {
+	queryID, err := r.Client.StartQuery(query)
+	if err != nil {
+		return client.InsightsResults{}, err
+	}
+
+	deadline := time.Now().Add(r.Timeout)
+	for {
+		results, err := r.Client.GetQueryResults(queryID)
+		if err != nil {
+			return results, err
+		}
+
+		switch cwltypes.QueryStatus(results.Status) {
+		case cwltypes.QueryStatusComplete:
+			return results, nil
+		case cwltypes.QueryStatusScheduled, cwltypes.QueryStatusRunning:
+		default:
+			return results, fmt.Errorf("query %s is %s", queryID, results.Status)
+		}
+
+		if r.Timeout > 0 && time.Now().After(deadline) {
+			_ = r.Client.StopQuery(queryID)
+			return results, fmt.Errorf("%w after %s, query %s was stopped", ErrQueryTimeout, r.Timeout, queryID)
+		}
+
+		select {
+		case <-r.Interrupt:
+			_ = r.Client.StopQuery(queryID)
+			return results, fmt.Errorf("%w, query %s was stopped", ErrQueryInterrupted, queryID)
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// QueryColumns returns a table column for each field of the results.
+func QueryColumns(fields []string) []output.Column[map[string]string] {
+	var columns []output.Column[map[string]string]
+	for _, field := range fields {
+		field := field
+		columns = append(columns, output.Column[map[string]string]{
+			Header: field,
+			Value:  func(row map[string]string) string { return row[field] },
+		})
+	}
+	return columns
+}
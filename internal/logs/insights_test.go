@@ -0,0 +1,105 @@
+package logs
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"going/internal/client"
+)
+
+type fakeQueryClient struct {
+	statuses []string
+	startErr error
+	polls    int
+	stopped  bool
+}
+
+func (c *fakeQueryClient) StartQuery(client.InsightsQuery) (string, error) {
+	return "query-1", c.startErr
+}
+
+func (c *fakeQueryClient) GetQueryResults(string) (client.InsightsResults, error) {
+	status := c.statuses[len(c.statuses)-1]
+	if c.polls < len(c.statuses) {
+		status = c.statuses[c.polls]
+	}
+	c.polls++
+	return client.InsightsResults{Status: status, Fields: []string{"path"}}, nil
+}
+
+func (c *fakeQueryClient) StopQuery(string) error {
+	c.stopped = true
+	return nil
+}
+
+func TestQueryRunner(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      *fakeQueryClient
+		timeout     time.Duration
+		interrupted bool
+		wantErr     error
+		wantPolls   int
+		wantStopped bool
+	}{
+		{
+			name:      "completes",
+			client:    &fakeQueryClient{statuses: []string{"Scheduled", "Running", "Complete"}},
+			wantPolls: 3,
+		},
+		{
+			name:      "fails",
+			client:    &fakeQueryClient{statuses: []string{"Running", "Failed"}},
+			wantErr:   errors.New("query query-1 is Failed"),
+			wantPolls: 2,
+		},
+		{
+			name:    "start fails",
+			client:  &fakeQueryClient{startErr: errors.New("MalformedQueryException")},
+			wantErr: errors.New("MalformedQueryException"),
+		},
+		{
+			name:        "times out",
+			client:      &fakeQueryClient{statuses: []string{"Running"}},
+			timeout:     time.Nanosecond,
+			wantErr:     ErrQueryTimeout,
+			wantPolls:   1,
+			wantStopped: true,
+		},
+		{
+			name:        "interrupted",
+			client:      &fakeQueryClient{statuses: []string{"Running"}},
+			interrupted: true,
+			wantErr:     ErrQueryInterrupted,
+			wantPolls:   1,
+			wantStopped: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interrupts := make(chan os.Signal, 1)
+			if tt.interrupted {
+				interrupts <- os.Interrupt
+			}
+			// The interval is long so an interrupted query can only stop by the interrupt.
+			r := &QueryRunner{Client: tt.client, Timeout: tt.timeout, Interval: time.Minute, Interrupt: interrupts}
+			_, err := r.Run(client.InsightsQuery{Query: "stats count(*) by path"})
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Run() unexpected error = %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("Run() expected error %v", tt.wantErr)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error():
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.client.polls != tt.wantPolls {
+				t.Errorf("Run() polled %d times, want %d", tt.client.polls, tt.wantPolls)
+			}
+			if tt.client.stopped != tt.wantStopped {
+				t.Errorf("Run() stopped = %v, want %v", tt.client.stopped, tt.wantStopped)
+			}
+		})
+	}
+}
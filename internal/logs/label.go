@@ -0,0 +1,59 @@
+package logs
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+)
+
+// shortIDLength is how much of the task ID is shown in labels.
+const shortIDLength = 8
+
+// labelColors are cycled through for each task.
+var labelColors = []func(interface{}) string{
+	promptui.Styler(promptui.FGCyan),
+	promptui.Styler(promptui.FGMagenta),
+	promptui.Styler(promptui.FGGreen),
+	promptui.Styler(promptui.FGYellow),
+	promptui.Styler(promptui.FGBlue),
+	promptui.Styler(promptui.FGRed),
+}
+
+// Labeler labels the streams of interleaved logs with a short task ID and
+// container name. Each task gets its own color.
+type Labeler struct {
+	// Color is false when the labels shouldn't be colored.
+	Color bool
+
+	mu     sync.Mutex
+	colors map[string]func(interface{}) string
+}
+
+// Label returns the label of the stream.
+func (l *Labeler) Label(s Stream) string {
+	label := fmt.Sprintf("%s %s", ShortID(s.TaskID), s.Container)
+	if !l.Color {
+		return label
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.colors == nil {
+		l.colors = map[string]func(interface{}) string{}
+	}
+	color, ok := l.colors[s.TaskID]
+	if !ok {
+		color = labelColors[len(l.colors)%len(labelColors)]
+		l.colors[s.TaskID] = color
+	}
+	return color(label)
+}
+
+// ShortID shortens a task ID for display.
+func ShortID(taskID string) string {
+	if len(taskID) > shortIDLength {
+		return taskID[:shortIDLength]
+	}
+	return taskID
+}
@@ -0,0 +1,263 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/manifoldco/promptui"
+)
+
+// The styles messages can be rendered in.
+const (
+	// StyleRaw prints messages as they are.
+	StyleRaw = "raw"
+	// StyleFlat prints structured messages on one line as level time msg key=value.
+	StyleFlat = "flat"
+	// StylePretty prints structured messages as indented JSON.
+	StylePretty = "pretty"
+)
+
+// Styles are the styles a Renderer accepts.
+var Styles = []string{StyleRaw, StyleFlat, StylePretty}
+
+// The keys structured loggers commonly use for the level, time, and message.
+var (
+	levelKeys   = []string{"level", "lvl", "severity", "log.level"}
+	timeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+	messageKeys = []string{"msg", "message"}
+)
+
+// Format decodes log messages of one structured format into their fields.
+// Decode returns false for messages that aren't in the format.
+type Format interface {
+	Name() string
+	Decode(message string) (map[string]interface{}, bool)
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   []Format
+)
+
+func init() {
+	RegisterFormat(JSONFormat{})
+	RegisterFormat(LogfmtFormat{})
+}
+
+// RegisterFormat adds a format to the ones messages are decoded with. Formats
+// are tried in the order they were registered.
+func RegisterFormat(f Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats = append(formats, f)
+}
+
+// registeredFormats returns a copy of the registered formats.
+func registeredFormats() []Format {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	return append([]Format(nil), formats...)
+}
+
+// Renderer renders structured log messages. Messages that none of the
+// formats decode are passed through unchanged.
+type Renderer struct {
+	Style string
+	// Fields selects the fields to print, in order. Nested fields are
+	// separated by dots, e.g. http.status.
+	Fields []string
+	// Color colors the level of flat messages.
+	Color bool
+
+	formats []Format
+}
+
+// NewRenderer returns a renderer using the registered formats.
+func NewRenderer(style string, fields []string, color bool) (*Renderer, error) {
+	switch style {
+	case StyleRaw, StyleFlat, StylePretty:
+	default:
+		return nil, fmt.Errorf("invalid render style '%s', use one of %s", style, strings.Join(Styles, ", "))
+	}
+	return &Renderer{Style: style, Fields: fields, Color: color, formats: registeredFormats()}, nil
+}
+
+// Render returns the message in the renderer's style. A nil Renderer
+// returns the message unchanged.
+func (r *Renderer) Render(message string) string {
+	if r == nil || r.Style == StyleRaw {
+		return message
+	}
+
+	fields, ok := r.decode(message)
+	if !ok {
+		return message
+	}
+
+	if r.Style == StylePretty {
+		return r.pretty(fields)
+	}
+	return r.flat(flatten(fields))
+}
+
+func (r *Renderer) decode(message string) (map[string]interface{}, bool) {
+	for _, f := range r.formats {
+		if fields, ok := f.Decode(message); ok {
+			return fields, true
+		}
+	}
+	return nil, false
+}
+
+// pretty indents the fields, only the selected ones if Fields is set.
+func (r *Renderer) pretty(fields map[string]interface{}) string {
+	var value interface{} = fields
+	if len(r.Fields) > 0 {
+		flat := flatten(fields)
+		selected := map[string]interface{}{}
+		for _, name := range r.Fields {
+			if v, ok := flat[name]; ok {
+				selected[name] = v
+			}
+		}
+		value = selected
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(fields)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// flat renders the fields on one line. Without Fields the level, time, and
+// message come first followed by the other fields sorted by name.
+func (r *Renderer) flat(fields map[string]interface{}) string {
+	var parts []string
+	if len(r.Fields) > 0 {
+		for _, name := range r.Fields {
+			v, ok := fields[name]
+			if !ok {
+				continue
+			}
+			value := formatValue(v)
+			if contains(levelKeys, name) {
+				value = r.level(value)
+			}
+			parts = append(parts, name+"="+value)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	used := map[string]bool{}
+	if key, ok := firstKey(fields, levelKeys); ok {
+		parts = append(parts, r.level(formatValue(fields[key])))
+		used[key] = true
+	}
+	if key, ok := firstKey(fields, timeKeys); ok {
+		parts = append(parts, formatValue(fields[key]))
+		used[key] = true
+	}
+	if key, ok := firstKey(fields, messageKeys); ok {
+		parts = append(parts, fmt.Sprint(fields[key]))
+		used[key] = true
+	}
+
+	var names []string
+	for name := range fields {
+		if !used[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parts = append(parts, name+"="+formatValue(fields[name]))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// level upper cases the level and colors it by severity.
+func (r *Renderer) level(level string) string {
+	level = strings.ToUpper(strings.Trim(level, `"`))
+	if !r.Color {
+		return level
+	}
+
+	switch level {
+	case "ERROR", "ERR", "FATAL", "PANIC", "CRITICAL", "CRIT", "ALERT", "EMERGENCY":
+		return promptui.Styler(promptui.FGRed, promptui.FGBold)(level)
+	case "WARN", "WARNING":
+		return promptui.Styler(promptui.FGYellow)(level)
+	case "INFO", "NOTICE":
+		return promptui.Styler(promptui.FGGreen)(level)
+	case "DEBUG", "TRACE":
+		return promptui.Styler(promptui.FGFaint)(level)
+	default:
+		return level
+	}
+}
+
+// flatten joins the keys of nested objects with dots.
+func flatten(fields map[string]interface{}) map[string]interface{} {
+	flat := map[string]interface{}{}
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+				walk(prefix+k+".", nested)
+				continue
+			}
+			flat[prefix+k] = v
+		}
+	}
+	walk("", fields)
+	return flat
+}
+
+// formatValue formats a field value, quoting strings with spaces and
+// encoding arrays and objects as JSON.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	case []interface{}, map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func firstKey(fields map[string]interface{}, keys []string) (string, bool) {
+	for _, k := range keys {
+		if _, ok := fields[k]; ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
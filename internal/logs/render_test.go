@@ -0,0 +1,85 @@
+package logs
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderer(t *testing.T) {
+	jsonLine := `{"level":"error","time":"2024-03-10T09:30:00Z","msg":"request failed","http":{"path":"/users","status":500},"retry":true}`
+	tests := []struct {
+		name    string
+		style   string
+		fields  []string
+		message string
+		want    string
+	}{
+		{name: "raw", style: StyleRaw, message: jsonLine, want: jsonLine},
+		{
+			name:    "flat json",
+			style:   StyleFlat,
+			message: jsonLine,
+			want:    `ERROR 2024-03-10T09:30:00Z request failed http.path=/users http.status=500 retry=true`,
+		},
+		{
+			name:    "flat json fields",
+			style:   StyleFlat,
+			fields:  []string{"http.status", "level", "missing"},
+			message: jsonLine,
+			want:    `http.status=500 level=ERROR`,
+		},
+		{
+			name:    "flat logfmt",
+			style:   StyleFlat,
+			message: `ts=2024-03-10T09:30:00Z lvl=warn msg="slow query" duration=1.5s table=users`,
+			want:    `WARN 2024-03-10T09:30:00Z slow query duration=1.5s table=users`,
+		},
+		{
+			name:    "pretty fields",
+			style:   StylePretty,
+			fields:  []string{"msg", "http.status"},
+			message: jsonLine,
+			want:    "{\n  \"http.status\": 500,\n  \"msg\": \"request failed\"\n}",
+		},
+		{name: "plain text", style: StyleFlat, message: "Listening on :8080", want: "Listening on :8080"},
+		{name: "json array", style: StyleFlat, message: `[1, 2]`, want: `[1, 2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRenderer(tt.style, tt.fields, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Render(tt.message); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewRenderer("yaml", nil, false); err == nil {
+		t.Error("NewRenderer() expected an error for an unknown style")
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	tests := []struct {
+		message string
+		want    map[string]interface{}
+		wantOK  bool
+	}{
+		{message: `a=1 b="two words" c=`, want: map[string]interface{}{"a": "1", "b": "two words", "c": ""}, wantOK: true},
+		{message: `msg="say \"hi\""`, want: map[string]interface{}{"msg": `say "hi"`}, wantOK: true},
+		{message: `GET /health 200`},
+		{message: `status=200 OK`},
+		{message: `a="unterminated`},
+		{message: ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got, ok := LogfmtFormat{}.Decode(tt.message)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
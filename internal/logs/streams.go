@@ -0,0 +1,119 @@
+package logs
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+	"going/internal/taskdef"
+)
+
+// maxStreamNames is the most stream names FilterLogEvents accepts.
+const maxStreamNames = 100
+
+// Stream is the log stream of a container in a task.
+type Stream struct {
+	GroupName string
+	Name      string
+	TaskID    string
+	Container string
+	// Missing is true while the stream doesn't exist, before the container
+	// logs its first event.
+	Missing bool
+}
+
+// Streams returns the log streams of every container of the tasks. The
+// definitions are the task definitions of the tasks by ARN. Containers that
+// don't log to CloudWatch are returned as errors so they can be reported.
+func Streams(tasks []client.Task, definitions map[string]*types.TaskDefinition) ([]Stream, []error) {
+	var streams []Stream
+	var errs []error
+	for _, t := range tasks {
+		definition, ok := definitions[t.DefinitionARN]
+		if !ok {
+			errs = append(errs, fmt.Errorf("no task definition for task %s", t.ID()))
+			continue
+		}
+
+		for _, c := range t.Containers {
+			config, err := logConfig(definition, c.Name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("task %s: %w", t.ID(), err))
+				continue
+			}
+			name, err := StreamName(config, c)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("task %s: %w", t.ID(), err))
+				continue
+			}
+
+			streams = append(streams, Stream{
+				GroupName: config.GroupName,
+				Name:      name,
+				TaskID:    t.ID(),
+				Container: c.Name,
+			})
+		}
+	}
+	return streams, errs
+}
+
+// StreamName returns the log stream of the container. Without a stream
+// prefix, which is only possible on EC2, awslogs names the stream after the
+// Docker container ID. Streams named by a FireLens template can't be known.
+func StreamName(config taskdef.LogConfig, c client.Container) (string, error) {
+	switch {
+	case config.Templated:
+		return "", fmt.Errorf("the FireLens stream names of container '%s' are templated", c.Name)
+	case !config.FireLens && config.StreamPrefix == "" && c.RuntimeID == "":
+		return "", fmt.Errorf("container '%s' hasn't started, its stream is named after its container ID", c.Name)
+	case !config.FireLens && config.StreamPrefix == "":
+		return c.RuntimeID, nil
+	default:
+		return config.StreamName(c.Name, c.TaskID()), nil
+	}
+}
+
+// Queries groups the streams into as few queries as FilterLogEvents allows,
+// one or more for each log group. FilterLogEvents fails for stream names
+// that don't exist, so each missing stream is queried by its name as a
+// prefix instead, which matches it once it's created.
+func Queries(streams []Stream) []client.LogQuery {
+	var groups []string
+	var missing []client.LogQuery
+	byGroup := map[string][]string{}
+	for _, s := range streams {
+		if s.Missing {
+			missing = append(missing, client.LogQuery{GroupName: s.GroupName, StreamPrefix: s.Name})
+			continue
+		}
+		if _, ok := byGroup[s.GroupName]; !ok {
+			groups = append(groups, s.GroupName)
+		}
+		byGroup[s.GroupName] = append(byGroup[s.GroupName], s.Name)
+	}
+
+	var queries []client.LogQuery
+	for _, group := range groups {
+		names := byGroup[group]
+		for start := 0; start < len(names); start += maxStreamNames {
+			end := start + maxStreamNames
+			if end > len(names) {
+				end = len(names)
+			}
+			queries = append(queries, client.LogQuery{GroupName: group, StreamNames: names[start:end]})
+		}
+	}
+	return append(queries, missing...)
+}
+
+func logConfig(definition *types.TaskDefinition, container string) (taskdef.LogConfig, error) {
+	for _, c := range definition.ContainerDefinitions {
+		if aws.ToString(c.Name) == container {
+			return taskdef.CloudWatchLogs(c)
+		}
+	}
+	return taskdef.LogConfig{}, fmt.Errorf("no container '%s' in task definition %s", container, taskdef.Name(definition))
+}
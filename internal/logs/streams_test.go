@@ -0,0 +1,173 @@
+package logs
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+	"going/internal/taskdef"
+)
+
+func TestStreams(t *testing.T) {
+	awslogs := func(group string) *types.LogConfiguration {
+		return &types.LogConfiguration{
+			LogDriver: types.LogDriverAwslogs,
+			Options:   map[string]string{"awslogs-group": group, "awslogs-stream-prefix": "ecs"},
+		}
+	}
+	definitions := map[string]*types.TaskDefinition{
+		"api:1": {
+			Family:   aws.String("api"),
+			Revision: 1,
+			ContainerDefinitions: []types.ContainerDefinition{
+				{Name: aws.String("web"), LogConfiguration: awslogs("/ecs/api")},
+				{Name: aws.String("envoy"), LogConfiguration: awslogs("/ecs/envoy")},
+				{Name: aws.String("xray")},
+			},
+		},
+	}
+	tasks := []client.Task{
+		{
+			ARN:           "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa",
+			DefinitionARN: "api:1",
+			Containers: []client.Container{
+				{Name: "web", TaskARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa"},
+				{Name: "envoy", TaskARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa"},
+				{Name: "xray", TaskARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa"},
+			},
+		},
+		{
+			ARN:           "arn:aws:ecs:eu-west-1:123456789012:task/main/bbb",
+			DefinitionARN: "api:1",
+			Containers:    []client.Container{{Name: "web", TaskARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/bbb"}},
+		},
+		{
+			ARN:           "arn:aws:ecs:eu-west-1:123456789012:task/main/ccc",
+			DefinitionARN: "api:2",
+			Containers:    []client.Container{{Name: "web"}},
+		},
+	}
+
+	streams, errs := Streams(tasks, definitions)
+
+	want := []Stream{
+		{GroupName: "/ecs/api", Name: "ecs/web/aaa", TaskID: "aaa", Container: "web"},
+		{GroupName: "/ecs/envoy", Name: "ecs/envoy/aaa", TaskID: "aaa", Container: "envoy"},
+		{GroupName: "/ecs/api", Name: "ecs/web/bbb", TaskID: "bbb", Container: "web"},
+	}
+	if !reflect.DeepEqual(streams, want) {
+		t.Errorf("Streams() got = %+v, want %+v", streams, want)
+	}
+	// The xray container has no log configuration and the last task's definition is missing.
+	if len(errs) != 2 {
+		t.Errorf("Streams() got %d errors, want 2: %v", len(errs), errs)
+	}
+}
+
+func TestStreamName(t *testing.T) {
+	c := client.Container{Name: "web", TaskARN: "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa", RuntimeID: "d0c4e2"}
+	tests := []struct {
+		name    string
+		config  taskdef.LogConfig
+		pending bool
+		want    string
+		wantErr bool
+	}{
+		{name: "prefix", config: taskdef.LogConfig{GroupName: "/ecs/api", StreamPrefix: "ecs"}, want: "ecs/web/aaa"},
+		{name: "no prefix", config: taskdef.LogConfig{GroupName: "/ecs/api"}, want: "d0c4e2"},
+		{name: "no prefix not started", config: taskdef.LogConfig{GroupName: "/ecs/api"}, pending: true, wantErr: true},
+		{
+			name:   "firelens",
+			config: taskdef.LogConfig{GroupName: "/ecs/api", StreamPrefix: "app-", FireLens: true},
+			want:   "app-web-firelens-aaa",
+		},
+		{
+			name:   "firelens no prefix",
+			config: taskdef.LogConfig{GroupName: "/ecs/api", FireLens: true},
+			want:   "web-firelens-aaa",
+		},
+		{
+			name:    "firelens template",
+			config:  taskdef.LogConfig{GroupName: "/ecs/api", StreamPrefix: "api/", FireLens: true, Templated: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := c
+			if tt.pending {
+				c.RuntimeID = ""
+			}
+			got, err := StreamName(tt.config, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("StreamName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueries(t *testing.T) {
+	var many []Stream
+	for i := 0; i < maxStreamNames+1; i++ {
+		many = append(many, Stream{GroupName: "/ecs/api", Name: fmt.Sprintf("ecs/web/%d", i)})
+	}
+
+	tests := []struct {
+		name    string
+		streams []Stream
+		want    []int
+	}{
+		{
+			name: "one query per group",
+			streams: []Stream{
+				{GroupName: "/ecs/api", Name: "ecs/web/aaa"},
+				{GroupName: "/ecs/envoy", Name: "ecs/envoy/aaa"},
+				{GroupName: "/ecs/api", Name: "ecs/web/bbb"},
+			},
+			want: []int{2, 1},
+		},
+		{
+			name:    "split at the stream name limit",
+			streams: many,
+			want:    []int{maxStreamNames, 1},
+		},
+		{
+			name: "missing streams by prefix",
+			streams: []Stream{
+				{GroupName: "/ecs/api", Name: "ecs/web/aaa"},
+				{GroupName: "/ecs/api", Name: "ecs/envoy/aaa", Missing: true},
+				{GroupName: "/ecs/api", Name: "ecs/web/bbb"},
+			},
+			want: []int{2, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, q := range Queries(tt.streams) {
+				got = append(got, len(q.StreamNames))
+				if q.StreamPrefix != "" && len(q.StreamNames) > 0 {
+					t.Errorf("Queries() got both stream names and prefix %s", q.StreamPrefix)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Queries() got stream counts %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabeler_Label(t *testing.T) {
+	l := &Labeler{}
+	got := l.Label(Stream{TaskID: "0123456789abcdef", Container: "web"})
+	if got != "01234567 web" {
+		t.Errorf("Label() got = %v, want %v", got, "01234567 web")
+	}
+}
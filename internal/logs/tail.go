@@ -0,0 +1,95 @@
+package logs
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"going/internal/client"
+)
+
+// Tailer tails the log events matching a query until it fails.
+type Tailer interface {
+	TailLogs(query client.LogQuery, eventHandler func(event client.LogEvent)) error
+}
+
+// TailerFunc adapts a function to a Tailer.
+type TailerFunc func(query client.LogQuery, eventHandler func(event client.LogEvent)) error
+
+// TailLogs calls f.
+func (f TailerFunc) TailLogs(query client.LogQuery, eventHandler func(event client.LogEvent)) error {
+	return f(query, eventHandler)
+}
+
+// Fallback tails with Live and switches to Poll if Live fails, e.g. when
+// Live Tail isn't allowed or available in the region. Poll continues from
+// the last event Live delivered.
+type Fallback struct {
+	Live Tailer
+	Poll Tailer
+	// OnFallback is called once with the error of the first Live failure.
+	OnFallback func(err error)
+
+	once sync.Once
+}
+
+// NewTailer returns a tailer streaming with Live Tail and falling back to
+// polling FilterLogEvents, or only polling when poll is true. A warning is
+// written to out when it falls back.
+func NewTailer(c *client.AWSClient, poll bool, out io.Writer) Tailer {
+	if poll {
+		return TailerFunc(c.PollLogs)
+	}
+
+	return &Fallback{
+		Live: TailerFunc(c.LiveTailLogs),
+		Poll: TailerFunc(c.PollLogs),
+		OnFallback: func(err error) {
+			_, _ = fmt.Fprintf(out, "Warning: Live Tail failed, polling for log events instead: %s\n", err)
+		},
+	}
+}
+
+// TailLogs tails the query with Live, then with Poll once Live fails.
+func (f *Fallback) TailLogs(query client.LogQuery, eventHandler func(event client.LogEvent)) error {
+	// The events at the last timestamp are remembered as polling starts
+	// from that timestamp again.
+	var last time.Time
+	var atLast map[string]struct{}
+	err := f.Live.TailLogs(query, func(e client.LogEvent) {
+		if e.Timestamp.After(last) {
+			last = e.Timestamp
+			atLast = map[string]struct{}{}
+		}
+		if e.Timestamp.Equal(last) {
+			atLast[eventKey(e)] = struct{}{}
+		}
+		eventHandler(e)
+	})
+	if err == nil {
+		return nil
+	}
+
+	if f.OnFallback != nil {
+		f.once.Do(func() { f.OnFallback(err) })
+	}
+
+	if last.After(query.StartTime) {
+		query.StartTime = last
+	}
+	return f.Poll.TailLogs(query, func(e client.LogEvent) {
+		if e.Timestamp.Equal(last) {
+			if _, ok := atLast[eventKey(e)]; ok {
+				return
+			}
+		}
+		eventHandler(e)
+	})
+}
+
+// eventKey identifies an event by its stream and message, Live Tail events
+// have no IDs.
+func eventKey(e client.LogEvent) string {
+	return e.StreamName + "\x00" + e.Message
+}
@@ -0,0 +1,84 @@
+package logs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"going/internal/client"
+)
+
+func TestFallback(t *testing.T) {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	event := func(seconds int, message string) client.LogEvent {
+		return client.LogEvent{StreamName: "ecs/web/aaa", Timestamp: start.Add(time.Duration(seconds) * time.Second), Message: message}
+	}
+	emit := func(events []client.LogEvent, err error) TailerFunc {
+		return func(query client.LogQuery, eventHandler func(event client.LogEvent)) error {
+			for _, e := range events {
+				if !e.Timestamp.Before(query.StartTime) {
+					eventHandler(e)
+				}
+			}
+			return err
+		}
+	}
+
+	tests := []struct {
+		name         string
+		live         TailerFunc
+		poll         TailerFunc
+		want         []string
+		wantErr      bool
+		wantFallback bool
+	}{
+		{
+			name: "live tail succeeds",
+			live: emit([]client.LogEvent{event(1, "a"), event(2, "b")}, nil),
+			poll: emit([]client.LogEvent{event(3, "c")}, nil),
+			want: []string{"a", "b"},
+		},
+		{
+			name:         "live tail unavailable",
+			live:         emit(nil, errors.New("AccessDeniedException")),
+			poll:         emit([]client.LogEvent{event(1, "a"), event(2, "b")}, nil),
+			want:         []string{"a", "b"},
+			wantFallback: true,
+		},
+		{
+			name:         "polling continues from the last event",
+			live:         emit([]client.LogEvent{event(1, "a"), event(2, "b")}, errors.New("SessionStreamingException")),
+			poll:         emit([]client.LogEvent{event(1, "a"), event(2, "b"), event(2, "c"), event(3, "d")}, nil),
+			want:         []string{"a", "b", "c", "d"},
+			wantFallback: true,
+		},
+		{
+			name:         "polling fails too",
+			live:         emit(nil, errors.New("AccessDeniedException")),
+			poll:         emit(nil, errors.New("ResourceNotFoundException")),
+			wantErr:      true,
+			wantFallback: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fellBack bool
+			f := &Fallback{Live: tt.live, Poll: tt.poll, OnFallback: func(error) { fellBack = true }}
+
+			var got []string
+			err := f.TailLogs(client.LogQuery{GroupName: "/ecs/api", StartTime: start}, func(e client.LogEvent) {
+				got = append(got, e.Message)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TailLogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TailLogs() events = %v, want %v", got, tt.want)
+			}
+			if fellBack != tt.wantFallback {
+				t.Errorf("TailLogs() fell back = %v, want %v", fellBack, tt.wantFallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package logs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// absoluteLayouts are the timestamp formats ParseTime accepts, tried in order.
+// Layouts without a zone are in local time.
+var absoluteLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the times of day accepted after today or yesterday.
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// ParseTime parses a point in time relative to now. It accepts:
+//
+//   - RFC3339 timestamps and dates like 2024-01-02 or 2024-01-02 15:04
+//   - durations back from now like 90m, 2h30m, or 3d
+//   - now, today, or yesterday, optionally followed by a time of day like
+//     yesterday 14:00, and a time of day on its own for today
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if d, ok := parseDuration(s); ok {
+		return now.Add(-d), nil
+	}
+
+	day, clock, _ := strings.Cut(strings.ToLower(s), " ")
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch day {
+	case "now":
+		if clock == "" {
+			return now, nil
+		}
+	case "today":
+		return atClock(midnight, clock, s)
+	case "yesterday":
+		return atClock(midnight.AddDate(0, 0, -1), clock, s)
+	default:
+		if clock == "" {
+			return atClock(midnight, day, s)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time '%s', use RFC3339, a duration like 2h, or a day like 'yesterday 14:00'", s)
+}
+
+// parseDuration parses a Go duration, also accepting whole days like 3d.
+func parseDuration(s string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// atClock returns the time of day on the day starting at midnight, midnight
+// itself if clock is empty.
+func atClock(midnight time.Time, clock string, s string) (time.Time, error) {
+	if clock == "" {
+		return midnight, nil
+	}
+
+	for _, layout := range clockLayouts {
+		t, err := time.Parse(layout, clock)
+		if err == nil {
+			return time.Date(midnight.Year(), midnight.Month(), midnight.Day(),
+				t.Hour(), t.Minute(), t.Second(), 0, midnight.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time of day in '%s', use HH:MM or HH:MM:SS", s)
+}
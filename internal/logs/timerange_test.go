@@ -0,0 +1,46 @@
+package logs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{input: "2024-03-08T14:00:00Z", want: time.Date(2024, 3, 8, 14, 0, 0, 0, time.UTC)},
+		{input: "2024-03-08T14:00:00+02:00", want: time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{input: "2024-03-08 14:05", want: time.Date(2024, 3, 8, 14, 5, 0, 0, time.UTC)},
+		{input: "2024-03-08", want: time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{input: "90m", want: time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC)},
+		{input: "2h30m", want: time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC)},
+		{input: "3d", want: time.Date(2024, 3, 7, 9, 30, 0, 0, time.UTC)},
+		{input: "now", want: now},
+		{input: "today", want: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{input: "yesterday", want: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{input: "yesterday 14:00", want: time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC)},
+		{input: "Yesterday 14:00:30", want: time.Date(2024, 3, 9, 14, 0, 30, 0, time.UTC)},
+		{input: "today 08:15", want: time.Date(2024, 3, 10, 8, 15, 0, 0, time.UTC)},
+		{input: "08:15", want: time.Date(2024, 3, 10, 8, 15, 0, 0, time.UTC)},
+		{input: "", wantErr: true},
+		{input: "-2h", wantErr: true},
+		{input: "yesterday noon", wantErr: true},
+		{input: "now 14:00", wantErr: true},
+		{input: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTime(tt.input, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
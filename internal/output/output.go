@@ -0,0 +1,147 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// The supported output formats.
+const (
+	FormatTable = "table"
+	FormatWide  = "wide"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatCSV   = "csv"
+)
+
+// Formats are the valid values for the output flag.
+var Formats = []string{FormatTable, FormatWide, FormatJSON, FormatYAML, FormatCSV}
+
+// Options are the output flags of a listing command.
+type Options struct {
+	Format   string
+	Template string
+}
+
+// Column is a column of a table. Wide columns are only shown with the wide format.
+type Column[T any] struct {
+	Header string
+	Wide   bool
+	Value  func(T) string
+}
+
+// AddFlags adds the output flags to the command.
+func AddFlags(cmd *cobra.Command, o *Options) {
+	cmd.PersistentFlags().StringVarP(&o.Format, "output", "o", FormatTable,
+		"The output format: "+strings.Join(Formats, ", "))
+	cmd.PersistentFlags().StringVar(&o.Template, "template", "",
+		"A Go template executed for each item, overrides the output format")
+}
+
+// Print writes the items to w in the format from the options.
+func Print[T any](w io.Writer, o Options, items []T, columns []Column[T]) error {
+	// Always encode an empty list instead of null.
+	if items == nil {
+		items = []T{}
+	}
+
+	if o.Template != "" {
+		return printTemplate(w, o.Template, items)
+	}
+
+	switch o.Format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(items); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		return printCSV(w, items, columns)
+	case FormatTable, FormatWide, "":
+		return printTable(w, o.Format == FormatWide, items, columns)
+	default:
+		return fmt.Errorf("unknown output format '%s', use one of: %s", o.Format, strings.Join(Formats, ", "))
+	}
+}
+
+func printTemplate[T any](w io.Writer, text string, items []T) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse template, %w", err)
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(w, item); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printCSV writes every column, including the wide ones, as CSV.
+func printCSV[T any](w io.Writer, items []T, columns []Column[T]) error {
+	cw := csv.NewWriter(w)
+	var headers []string
+	for _, c := range columns {
+		headers = append(headers, c.Header)
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var values []string
+		for _, c := range columns {
+			values = append(values, c.Value(item))
+		}
+		if err := cw.Write(values); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func printTable[T any](w io.Writer, wide bool, items []T, columns []Column[T]) error {
+	var shown []Column[T]
+	for _, c := range columns {
+		if !c.Wide || wide {
+			shown = append(shown, c)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	var headers []string
+	for _, c := range shown {
+		headers = append(headers, c.Header)
+	}
+	_, _ = fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		var values []string
+		for _, c := range shown {
+			values = append(values, c.Value(item))
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type item struct {
+	Name  string `json:"name" yaml:"name"`
+	Image string `json:"image" yaml:"image"`
+}
+
+var columns = []Column[item]{
+	{Header: "NAME", Value: func(i item) string { return i.Name }},
+	{Header: "IMAGE", Wide: true, Value: func(i item) string { return i.Image }},
+}
+
+func TestPrint(t *testing.T) {
+	items := []item{{Name: "web", Image: "nginx"}, {Name: "app", Image: "app:1"}}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		items   []item
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "table",
+			opts:  Options{Format: FormatTable},
+			items: items,
+			want:  "NAME\nweb\napp\n",
+		},
+		{
+			name:  "wide table",
+			opts:  Options{Format: FormatWide},
+			items: items,
+			want:  "NAME   IMAGE\nweb    nginx\napp    app:1\n",
+		},
+		{
+			name:  "json",
+			opts:  Options{Format: FormatJSON},
+			items: items[:1],
+			want:  "[\n  {\n    \"name\": \"web\",\n    \"image\": \"nginx\"\n  }\n]\n",
+		},
+		{
+			name:  "json empty list",
+			opts:  Options{Format: FormatJSON},
+			items: nil,
+			want:  "[]\n",
+		},
+		{
+			name:  "yaml",
+			opts:  Options{Format: FormatYAML},
+			items: items[:1],
+			want:  "- name: web\n  image: nginx\n",
+		},
+		{
+			name:  "csv includes wide columns",
+			opts:  Options{Format: FormatCSV},
+			items: []item{{Name: "web", Image: "nginx"}, {Name: "app, v2", Image: "app:2"}},
+			want:  "NAME,IMAGE\nweb,nginx\n\"app, v2\",app:2\n",
+		},
+		{
+			name:  "template overrides format",
+			opts:  Options{Format: FormatJSON, Template: "{{ .Name }}={{ .Image }}"},
+			items: items,
+			want:  "web=nginx\napp=app:1\n",
+		},
+		{
+			name:    "invalid template",
+			opts:    Options{Template: "{{ .Name "},
+			items:   items,
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			opts:    Options{Format: "xml"},
+			items:   items,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Print(&buf, tt.opts, tt.items, columns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Print() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && buf.String() != tt.want {
+				t.Errorf("Print() got = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
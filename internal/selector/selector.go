@@ -0,0 +1,407 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/manifoldco/promptui"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/utils"
+)
+
+// Task selection policies for when a service has multiple tasks running.
+const (
+	TaskPolicyNewest  = "newest"
+	TaskPolicyRandom  = "random"
+	TaskPolicyHealthy = "healthy"
+)
+
+// TaskPolicies are the valid values for a task selection policy.
+var TaskPolicies = []string{TaskPolicyNewest, TaskPolicyRandom, TaskPolicyHealthy}
+
+// ErrNoTasks is returned when a service has no running tasks.
+var ErrNoTasks = errors.New("no tasks running")
+
+// ErrTaskNotRunning is returned when the task given isn't running.
+var ErrTaskNotRunning = errors.New("task isn't running")
+
+// ErrNoStoppedTasks is returned when a service has no recently stopped tasks.
+var ErrNoStoppedTasks = errors.New("no recently stopped tasks")
+
+var stoppedTaskPromptTemplate = &promptui.SelectTemplates{
+	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
+	Active:   fmt.Sprintf(`%s {{ .ID | underline }} {{ .Stopped | faint }}`, promptui.IconSelect),
+	Inactive: `  {{ .ID }} {{ .Stopped | faint }}`,
+	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .ID | faint }}`, promptui.IconGood),
+	Details: `{{ "Status:" | faint }} {{ .Task.LastStatus }}
+{{ "Stop code:" | faint }} {{ .Task.StopCode }}
+{{ "Reason:" | faint }} {{ .Task.StoppedReason }}
+{{ "Exit codes:" | faint }} {{ .ExitCodes }}`,
+}
+
+var containerPromptTemplate = &promptui.SelectTemplates{
+	Label:    fmt.Sprintf("%s {{ . }}: ", promptui.IconInitial),
+	Active:   fmt.Sprintf("%s {{ .Name | underline }}", promptui.IconSelect),
+	Inactive: "  {{ .Name }}",
+	Selected: fmt.Sprintf(`{{ "%s" | green }} {{ .Name | faint }}`, promptui.IconGood),
+	Details: `{{ "Status:" | faint }} {{ .LastStatus }}
+{{ "Health:" | faint }} {{ .Health }}`,
+}
+
+// Selector picks the cluster, service, task, and container to work with.
+// Values given by flags are used as is, otherwise the user is prompted. When
+// running non-interactively a missing value is an error that lists the
+// candidates.
+type Selector struct {
+	f      *factory.Factory
+	client ecsClient
+}
+
+// ecsClient is the part of the client the selector lists candidates with.
+type ecsClient interface {
+	ListClusters() ([]client.Cluster, error)
+	ListServices(cluster string) ([]client.Service, error)
+	ListTasks(cluster string, service string) ([]string, error)
+	ListStoppedTasks(cluster string, service string) ([]string, error)
+	DescribeTasks(cluster string, taskARNs ...string) ([]client.Task, error)
+	DescribeTask(cluster string, taskARN string) (client.Task, error)
+	DescribeContainers(cluster string, taskARN string) ([]client.Container, error)
+	DescribeContainer(cluster string, taskARN string, name string) (client.Container, error)
+}
+
+func New(f *factory.Factory, c *client.AWSClient) *Selector {
+	return &Selector{f: f, client: c}
+}
+
+// Cluster returns input or prompts for a cluster.
+func (s *Selector) Cluster(input string) (string, error) {
+	if input != "" {
+		return input, nil
+	}
+
+	c, err := s.client.ListClusters()
+	if err != nil {
+		return "", err
+	}
+
+	var clusters []string
+	for _, cluster := range c {
+		clusters = append(clusters, cluster.Name)
+	}
+
+	if s.f.NonInteractive {
+		return "", missingChoice("cluster", clusters)
+	}
+	return s.f.Prompt.Select("Select a cluster", clusters)
+}
+
+// Service returns input or prompts for a service in the cluster.
+func (s *Selector) Service(cluster string, input string) (string, error) {
+	if input != "" {
+		return input, nil
+	}
+
+	svc, err := s.client.ListServices(cluster)
+	if err != nil {
+		return "", err
+	}
+
+	var services []string
+	for _, service := range svc {
+		services = append(services, service.Name)
+	}
+
+	if s.f.NonInteractive {
+		return "", missingChoice("service", services)
+	}
+	return s.f.Prompt.Select("Select a service", services)
+}
+
+// Task returns the ARN of a running task of the service. The input can be a
+// task ID or ARN. When there are multiple tasks the policy is used to pick one,
+// if there is no policy the user is prompted.
+func (s *Selector) Task(cluster string, service string, input string, policy string) (string, error) {
+	t, err := s.client.ListTasks(cluster, service)
+	if err != nil {
+		return "", err
+	}
+
+	if input != "" {
+		for _, arn := range t {
+			if arn == input || strings.HasSuffix(arn, "/"+input) {
+				return arn, nil
+			}
+		}
+		return "", fmt.Errorf("%w, no running task '%s' for service '%s', the running tasks are: %s",
+			ErrTaskNotRunning, input, service, strings.Join(taskIDs(t), ", "))
+	}
+
+	switch len(t) {
+	case 0:
+		return "", fmt.Errorf("%w for service '%s'", ErrNoTasks, service)
+	case 1:
+		return t[0], nil
+	}
+
+	if policy != "" {
+		tasks, err := s.client.DescribeTasks(cluster, t...)
+		if err != nil {
+			return "", err
+		}
+		task, err := PickTask(tasks, policy)
+		if err != nil {
+			return "", err
+		}
+		return task.ARN, nil
+	}
+
+	if s.f.NonInteractive {
+		return "", fmt.Errorf("multiple tasks running for service '%s' and %w, use --task with one of: %s "+
+			"or --task-policy with one of: %s", service, utils.ErrNonInteractive,
+			strings.Join(taskIDs(t), ", "), strings.Join(TaskPolicies, ", "))
+	}
+	return s.f.Prompt.Select("Multiple tasks running, please select one", t)
+}
+
+// Container returns the container in the task with the name input or prompts for one.
+func (s *Selector) Container(cluster string, taskARN string, input string) (client.Container, error) {
+	if input != "" {
+		return s.client.DescribeContainer(cluster, taskARN, input)
+	}
+
+	containers, err := s.client.DescribeContainers(cluster, taskARN)
+	if err != nil {
+		return client.Container{}, err
+	}
+
+	if s.f.NonInteractive {
+		var names []string
+		for _, c := range containers {
+			names = append(names, c.Name)
+		}
+		return client.Container{}, missingChoice("container", names)
+	}
+
+	i, err := s.f.Prompt.CustomSelect("Select a container", containers, containerPromptTemplate, containerSearch(containers))
+	if err != nil {
+		return client.Container{}, err
+	}
+	return containers[i], nil
+}
+
+// ContainerDefinition returns the container in the task definition with the
+// name input or prompts for one. A task definition with a single container
+// doesn't need a name.
+func (s *Selector) ContainerDefinition(definition *types.TaskDefinition, input string) (types.ContainerDefinition, error) {
+	var names []string
+	for _, c := range definition.ContainerDefinitions {
+		if input != "" && aws.ToString(c.Name) == input {
+			return c, nil
+		}
+		names = append(names, aws.ToString(c.Name))
+	}
+
+	switch {
+	case input != "":
+		return types.ContainerDefinition{}, fmt.Errorf("no container '%s' in task definition '%s', the containers are: %s",
+			input, aws.ToString(definition.Family), strings.Join(names, ", "))
+	case len(names) == 1:
+		return definition.ContainerDefinitions[0], nil
+	case s.f.NonInteractive:
+		return types.ContainerDefinition{}, missingChoice("container", names)
+	}
+
+	name, err := s.f.Prompt.Select("Select a container", names)
+	if err != nil {
+		return types.ContainerDefinition{}, err
+	}
+	for _, c := range definition.ContainerDefinitions {
+		if aws.ToString(c.Name) == name {
+			return c, nil
+		}
+	}
+	return types.ContainerDefinition{}, fmt.Errorf("no container '%s' in task definition", name)
+}
+
+// PickTask picks a task using the policy.
+func PickTask(tasks []client.Task, policy string) (client.Task, error) {
+	if len(tasks) == 0 {
+		return client.Task{}, ErrNoTasks
+	}
+
+	// Newest first so the healthy policy also prefers the newest task.
+	sorted := make([]client.Task, len(tasks))
+	copy(sorted, tasks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartedAt.After(sorted[j].StartedAt)
+	})
+
+	switch policy {
+	case TaskPolicyNewest:
+		return sorted[0], nil
+	case TaskPolicyRandom:
+		return sorted[rand.Intn(len(sorted))], nil
+	case TaskPolicyHealthy:
+		for _, t := range sorted {
+			if t.Health == string(types.HealthStatusHealthy) {
+				return t, nil
+			}
+		}
+		// Without container health checks ECS reports the health as unknown,
+		// so a running task is the best there is.
+		for _, t := range sorted {
+			if t.LastStatus == string(types.DesiredStatusRunning) &&
+				(t.Health == "" || t.Health == string(types.HealthStatusUnknown)) {
+				return t, nil
+			}
+		}
+		return client.Task{}, fmt.Errorf("none of the %d tasks are healthy or running without a health check", len(tasks))
+	default:
+		return client.Task{}, fmt.Errorf("unknown task policy '%s', use one of: %s",
+			policy, strings.Join(TaskPolicies, ", "))
+	}
+}
+
+// StoppedTask is a recently stopped task as shown when picking one.
+type StoppedTask struct {
+	Task client.Task
+	ID   string
+	// Stopped is how long ago the task stopped.
+	Stopped string
+	// ExitCodes are the exit codes of the task's containers, with the reasons they stopped.
+	ExitCodes string
+}
+
+// NewStoppedTask summarizes a stopped task.
+func NewStoppedTask(t client.Task) StoppedTask {
+	st := StoppedTask{Task: t, ID: t.ID(), Stopped: "stopping"}
+	if !t.StoppedAt.IsZero() {
+		st.Stopped = "stopped " + ago(time.Since(t.StoppedAt))
+	}
+
+	var codes []string
+	for _, c := range t.Containers {
+		code := "-"
+		if c.ExitCode != nil {
+			code = fmt.Sprint(*c.ExitCode)
+		}
+		if c.Reason != "" {
+			code += fmt.Sprintf(" (%s)", c.Reason)
+		}
+		codes = append(codes, fmt.Sprintf("%s=%s", c.Name, code))
+	}
+	st.ExitCodes = strings.Join(codes, ", ")
+	return st
+}
+
+// StoppedTask returns a recently stopped task of the service, newest first.
+// The input can be a task ID or ARN, without it the user is prompted unless
+// the policy is newest, the only policy that applies to stopped tasks. ECS
+// only keeps stopped tasks for about an hour.
+func (s *Selector) StoppedTask(cluster string, service string, input string, policy string) (client.Task, error) {
+	if policy != "" && policy != TaskPolicyNewest {
+		return client.Task{}, fmt.Errorf("the %s task policy can't pick a stopped task, use --task-policy %s",
+			policy, TaskPolicyNewest)
+	}
+
+	arns, err := s.client.ListStoppedTasks(cluster, service)
+	if err != nil {
+		return client.Task{}, err
+	}
+
+	if input != "" {
+		for _, arn := range arns {
+			if arn == input || strings.HasSuffix(arn, "/"+input) {
+				return s.client.DescribeTask(cluster, arn)
+			}
+		}
+		return client.Task{}, fmt.Errorf("no recently stopped task '%s' for service '%s', ECS only keeps stopped tasks "+
+			"for about an hour", input, service)
+	}
+
+	if len(arns) == 0 {
+		return client.Task{}, fmt.Errorf("%w for service '%s'", ErrNoStoppedTasks, service)
+	}
+
+	tasks, err := s.client.DescribeTasks(cluster, arns...)
+	if err != nil {
+		return client.Task{}, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].StoppedAt.After(tasks[j].StoppedAt)
+	})
+
+	if len(tasks) == 1 || policy == TaskPolicyNewest {
+		return tasks[0], nil
+	}
+	if s.f.NonInteractive {
+		return client.Task{}, fmt.Errorf("multiple stopped tasks for service '%s' and %w, use --task with one of: %s "+
+			"or --task-policy newest", service, utils.ErrNonInteractive, strings.Join(taskIDs(arns), ", "))
+	}
+
+	var items []StoppedTask
+	for _, t := range tasks {
+		items = append(items, NewStoppedTask(t))
+	}
+	i, err := s.f.Prompt.CustomSelect("Select a stopped task", items, stoppedTaskPromptTemplate, stoppedTaskSearch(items))
+	if err != nil {
+		return client.Task{}, err
+	}
+	return tasks[i], nil
+}
+
+// ago formats a duration as a short human friendly time ago.
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+}
+
+func stoppedTaskSearch(tasks []StoppedTask) func(input string, index int) bool {
+	return func(input string, index int) bool {
+		item := tasks[index]
+		return fuzzy.MatchFold(input, item.ID) || fuzzy.MatchFold(input, item.Task.StoppedReason)
+	}
+}
+
+// missingChoice is the error for a value that has to be given by the flag with the same name.
+func missingChoice(flag string, candidates []string) error {
+	if len(candidates) == 0 {
+		return fmt.Errorf("no %s given and there are none to choose from", flag)
+	}
+	return fmt.Errorf("no %s given and %w, use --%s with one of: %s",
+		flag, utils.ErrNonInteractive, flag, strings.Join(candidates, ", "))
+}
+
+func taskIDs(arns []string) []string {
+	var ids []string
+	for _, arn := range arns {
+		id, _ := utils.Last(strings.Split(arn, "/"))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func containerSearch(containers []client.Container) func(input string, index int) bool {
+	return func(input string, index int) bool {
+		item := containers[index]
+		if fuzzy.MatchFold(input, item.Name) {
+			return true
+		}
+		return false
+	}
+}
@@ -0,0 +1,290 @@
+package selector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"going/internal/client"
+	"going/internal/factory"
+	"going/internal/utils"
+)
+
+func TestPickTask(t *testing.T) {
+	now := time.Now()
+	oldHealthy := client.Task{ARN: "old", Health: "HEALTHY", StartedAt: now.Add(-time.Hour)}
+	newUnhealthy := client.Task{ARN: "new", Health: "UNHEALTHY", StartedAt: now}
+	middleHealthy := client.Task{ARN: "middle", Health: "HEALTHY", StartedAt: now.Add(-time.Minute)}
+	oldUnknown := client.Task{ARN: "old-unknown", Health: "UNKNOWN", LastStatus: "RUNNING", StartedAt: now.Add(-time.Hour)}
+	newUnknown := client.Task{ARN: "new-unknown", Health: "UNKNOWN", LastStatus: "RUNNING", StartedAt: now}
+	pendingUnknown := client.Task{ARN: "pending", Health: "UNKNOWN", LastStatus: "PENDING", StartedAt: now}
+
+	tests := []struct {
+		name    string
+		tasks   []client.Task
+		policy  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "newest",
+			tasks:  []client.Task{oldHealthy, newUnhealthy, middleHealthy},
+			policy: TaskPolicyNewest,
+			want:   "new",
+		},
+		{
+			name:   "newest healthy",
+			tasks:  []client.Task{oldHealthy, newUnhealthy, middleHealthy},
+			policy: TaskPolicyHealthy,
+			want:   "middle",
+		},
+		{
+			name:    "no healthy tasks",
+			tasks:   []client.Task{newUnhealthy},
+			policy:  TaskPolicyHealthy,
+			wantErr: true,
+		},
+		{
+			name:   "healthy before unknown",
+			tasks:  []client.Task{newUnknown, middleHealthy},
+			policy: TaskPolicyHealthy,
+			want:   "middle",
+		},
+		{
+			name:   "no health checks",
+			tasks:  []client.Task{oldUnknown, pendingUnknown, newUnknown, newUnhealthy},
+			policy: TaskPolicyHealthy,
+			want:   "new-unknown",
+		},
+		{
+			name:    "no health checks and none running",
+			tasks:   []client.Task{pendingUnknown},
+			policy:  TaskPolicyHealthy,
+			wantErr: true,
+		},
+		{
+			name:   "random with one task",
+			tasks:  []client.Task{oldHealthy},
+			policy: TaskPolicyRandom,
+			want:   "old",
+		},
+		{
+			name:    "unknown policy",
+			tasks:   []client.Task{oldHealthy},
+			policy:  "oldest",
+			wantErr: true,
+		},
+		{
+			name:    "no tasks",
+			tasks:   nil,
+			policy:  TaskPolicyNewest,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PickTask(tt.tasks, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PickTask() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.ARN != tt.want {
+				t.Errorf("PickTask() got = %v, want %v", got.ARN, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewStoppedTask(t *testing.T) {
+	exitCode := func(code int32) *int32 { return &code }
+	tests := []struct {
+		name          string
+		task          client.Task
+		wantStopped   string
+		wantExitCodes string
+	}{
+		{
+			name: "crashed",
+			task: client.Task{
+				ARN:       "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa",
+				StoppedAt: time.Now().Add(-12 * time.Minute),
+				Containers: []client.Container{
+					{Name: "web", ExitCode: exitCode(137), Reason: "OutOfMemoryError: Container killed due to memory usage"},
+					{Name: "envoy", ExitCode: exitCode(0)},
+				},
+			},
+			wantStopped:   "stopped 12m ago",
+			wantExitCodes: "web=137 (OutOfMemoryError: Container killed due to memory usage), envoy=0",
+		},
+		{
+			name: "stopping",
+			task: client.Task{
+				ARN:        "arn:aws:ecs:eu-west-1:123456789012:task/main/bbb",
+				Containers: []client.Container{{Name: "web"}},
+			},
+			wantStopped:   "stopping",
+			wantExitCodes: "web=-",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewStoppedTask(tt.task)
+			if got.Stopped != tt.wantStopped {
+				t.Errorf("NewStoppedTask() Stopped = %v, want %v", got.Stopped, tt.wantStopped)
+			}
+			if got.ExitCodes != tt.wantExitCodes {
+				t.Errorf("NewStoppedTask() ExitCodes = %v, want %v", got.ExitCodes, tt.wantExitCodes)
+			}
+		})
+	}
+}
+
+func TestSelector_StoppedTaskPolicy(t *testing.T) {
+	s := &Selector{}
+	for _, policy := range []string{TaskPolicyRandom, TaskPolicyHealthy} {
+		if _, err := s.StoppedTask("main", "api", "", policy); err == nil {
+			t.Errorf("StoppedTask() with the %s policy should fail", policy)
+		}
+	}
+}
+
+type fakeECS struct {
+	clusters   []client.Cluster
+	services   []client.Service
+	tasks      []string
+	containers []client.Container
+}
+
+func (f fakeECS) ListClusters() ([]client.Cluster, error) { return f.clusters, nil }
+
+func (f fakeECS) ListServices(string) ([]client.Service, error) { return f.services, nil }
+
+func (f fakeECS) ListTasks(string, string) ([]string, error) { return f.tasks, nil }
+
+func (f fakeECS) ListStoppedTasks(string, string) ([]string, error) { return f.tasks, nil }
+
+func (f fakeECS) DescribeTasks(_ string, arns ...string) ([]client.Task, error) {
+	var tasks []client.Task
+	for _, arn := range arns {
+		tasks = append(tasks, client.Task{ARN: arn})
+	}
+	return tasks, nil
+}
+
+func (f fakeECS) DescribeTask(_ string, arn string) (client.Task, error) {
+	return client.Task{ARN: arn}, nil
+}
+
+func (f fakeECS) DescribeContainers(string, string) ([]client.Container, error) {
+	return f.containers, nil
+}
+
+func (f fakeECS) DescribeContainer(_ string, _ string, name string) (client.Container, error) {
+	return client.Container{Name: name}, nil
+}
+
+func TestMissingChoice(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+		wantNonInt bool
+	}{
+		{
+			name:       "candidates",
+			candidates: []string{"main", "staging"},
+			want:       "no cluster given and running non-interactively, use --cluster with one of: main, staging",
+			wantNonInt: true,
+		},
+		{
+			name: "no candidates",
+			want: "no cluster given and there are none to choose from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := missingChoice("cluster", tt.candidates)
+			if err.Error() != tt.want {
+				t.Errorf("missingChoice() = %v, want %v", err, tt.want)
+			}
+			if errors.Is(err, utils.ErrNonInteractive) != tt.wantNonInt {
+				t.Errorf("missingChoice() is ErrNonInteractive = %v, want %v", !tt.wantNonInt, tt.wantNonInt)
+			}
+		})
+	}
+}
+
+func TestSelector_NonInteractive(t *testing.T) {
+	ecs := fakeECS{
+		clusters:   []client.Cluster{{Name: "main"}, {Name: "staging"}},
+		services:   []client.Service{{Name: "api"}, {Name: "worker"}},
+		tasks:      []string{"arn:aws:ecs:eu-west-1:123456789012:task/main/aaa", "arn:aws:ecs:eu-west-1:123456789012:task/main/bbb"},
+		containers: []client.Container{{Name: "web"}, {Name: "envoy"}},
+	}
+	s := &Selector{f: &factory.Factory{NonInteractive: true, Prompt: utils.NonInteractivePrompter{}}, client: ecs}
+
+	tests := []struct {
+		name       string
+		run        func() (string, error)
+		want       string
+		wantNonInt bool
+	}{
+		{
+			name:       "cluster",
+			run:        func() (string, error) { return s.Cluster("") },
+			wantNonInt: true,
+		},
+		{
+			name: "cluster given",
+			run:  func() (string, error) { return s.Cluster("main") },
+			want: "main",
+		},
+		{
+			name:       "service",
+			run:        func() (string, error) { return s.Service("main", "") },
+			wantNonInt: true,
+		},
+		{
+			name: "service given",
+			run:  func() (string, error) { return s.Service("main", "api") },
+			want: "api",
+		},
+		{
+			name:       "multiple tasks",
+			run:        func() (string, error) { return s.Task("main", "api", "", "") },
+			wantNonInt: true,
+		},
+		{
+			name: "multiple tasks with a policy",
+			run:  func() (string, error) { return s.Task("main", "api", "", TaskPolicyNewest) },
+			want: "arn:aws:ecs:eu-west-1:123456789012:task/main/aaa",
+		},
+		{
+			name: "task given",
+			run:  func() (string, error) { return s.Task("main", "api", "bbb", "") },
+			want: "arn:aws:ecs:eu-west-1:123456789012:task/main/bbb",
+		},
+		{
+			name: "container",
+			run: func() (string, error) {
+				c, err := s.Container("main", "aaa", "")
+				return c.Name, err
+			},
+			wantNonInt: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run()
+			if errors.Is(err, utils.ErrNonInteractive) != tt.wantNonInt {
+				t.Fatalf("error = %v, want ErrNonInteractive %v", err, tt.wantNonInt)
+			}
+			if !tt.wantNonInt && err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package status
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+	"going/internal/utils"
+)
+
+// Headers are the columns of the rows returned by ServiceRows.
+var Headers = []string{"SERVICE", "RUNNING", "PENDING", "DESIRED", "ROLLOUT", "TASK DEFINITION", "CONTAINER", "HEALTH", "IMAGE"}
+
+// Event is a service event along with the service it is about.
+type Event struct {
+	Service   string
+	CreatedAt time.Time
+	Message   string
+}
+
+// TasksByService groups the tasks by the service that started them.
+func TasksByService(tasks []client.Task) map[string][]client.Task {
+	grouped := map[string][]client.Task{}
+	for _, t := range tasks {
+		grouped[t.ServiceName] = append(grouped[t.ServiceName], t)
+	}
+	return grouped
+}
+
+// ServiceRows returns a row for each container of the service. The service
+// columns are only filled in on the first row.
+func ServiceRows(service types.Service, tasks []client.Task) [][]string {
+	rollout := ""
+	for _, d := range service.Deployments {
+		if aws.ToString(d.Status) == "PRIMARY" {
+			rollout = string(d.RolloutState)
+		}
+	}
+
+	first := []string{
+		aws.ToString(service.ServiceName),
+		strconv.Itoa(int(service.RunningCount)),
+		strconv.Itoa(int(service.PendingCount)),
+		strconv.Itoa(int(service.DesiredCount)),
+		rollout,
+		taskDefinitionName(aws.ToString(service.TaskDefinition)),
+	}
+
+	containers := containerSummaries(tasks)
+	if len(containers) == 0 {
+		return [][]string{append(first, "", "", "")}
+	}
+
+	var rows [][]string
+	for i, c := range containers {
+		row := make([]string, len(first))
+		if i == 0 {
+			copy(row, first)
+		}
+		rows = append(rows, append(row, c...))
+	}
+	return rows
+}
+
+// RecentEvents returns up to max of the most recent events of each service,
+// newest first across all the services.
+func RecentEvents(services []types.Service, max int) []Event {
+	var events []Event
+	for _, service := range services {
+		// ECS returns the events of a service newest first.
+		for i, e := range service.Events {
+			if i >= max {
+				break
+			}
+			events = append(events, Event{
+				Service:   aws.ToString(service.ServiceName),
+				CreatedAt: aws.ToTime(e.CreatedAt),
+				Message:   aws.ToString(e.Message),
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	return events
+}
+
+// EventLines formats each event as its local time, service, and message with
+// the service names aligned. The time is passed through style.
+func EventLines(events []Event, style func(interface{}) string) []string {
+	width := 0
+	for _, e := range events {
+		if len(e.Service) > width {
+			width = len(e.Service)
+		}
+	}
+
+	var lines []string
+	for _, e := range events {
+		lines = append(lines, fmt.Sprintf("%s %-*s %s", style(e.CreatedAt.Local().Format(time.DateTime)),
+			width, e.Service, e.Message))
+	}
+	return lines
+}
+
+// containerSummaries returns the name, health counts, and images of each
+// container across all the tasks.
+func containerSummaries(tasks []client.Task) [][]string {
+	health := map[string]map[string]int{}
+	images := map[string]map[string]struct{}{}
+	var names []string
+	for _, t := range tasks {
+		for _, c := range t.Containers {
+			if _, ok := health[c.Name]; !ok {
+				names = append(names, c.Name)
+				health[c.Name] = map[string]int{}
+				images[c.Name] = map[string]struct{}{}
+			}
+			status := c.Health
+			if status == "" {
+				status = string(types.HealthStatusUnknown)
+			}
+			health[c.Name][status]++
+			images[c.Name][imageTag(c.Image)] = struct{}{}
+		}
+	}
+	sort.Strings(names)
+
+	var summaries [][]string
+	for _, name := range names {
+		var counts []string
+		for _, status := range sortedKeys(health[name]) {
+			counts = append(counts, fmt.Sprintf("%d %s", health[name][status], status))
+		}
+		summaries = append(summaries, []string{
+			name,
+			strings.Join(counts, ", "),
+			strings.Join(sortedKeys(images[name]), ", "),
+		})
+	}
+	return summaries
+}
+
+// taskDefinitionName returns the family and revision of the task definition ARN.
+func taskDefinitionName(arn string) string {
+	name, _ := utils.Last(strings.Split(arn, "/"))
+	return name
+}
+
+// imageTag returns the last part of the image name with its tag.
+func imageTag(image string) string {
+	name, _ := utils.Last(strings.Split(image, "/"))
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
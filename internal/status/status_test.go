@@ -0,0 +1,140 @@
+package status
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/client"
+)
+
+func TestServiceRows(t *testing.T) {
+	service := types.Service{
+		ServiceName:    aws.String("api"),
+		RunningCount:   2,
+		PendingCount:   1,
+		DesiredCount:   3,
+		TaskDefinition: aws.String("arn:aws:ecs:eu-west-1:123456789012:task-definition/api:7"),
+		Deployments: []types.Deployment{
+			{Status: aws.String("ACTIVE"), RolloutState: types.DeploymentRolloutStateCompleted},
+			{Status: aws.String("PRIMARY"), RolloutState: types.DeploymentRolloutStateInProgress},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		tasks []client.Task
+		want  [][]string
+	}{
+		{
+			name: "no tasks",
+			want: [][]string{{"api", "2", "1", "3", "IN_PROGRESS", "api:7", "", "", ""}},
+		},
+		{
+			name: "containers across tasks",
+			tasks: []client.Task{
+				{Containers: []client.Container{
+					{Name: "web", Health: "HEALTHY", Image: "registry.example.com/team/app:1"},
+					{Name: "envoy", Image: "envoy:v1"},
+				}},
+				{Containers: []client.Container{
+					{Name: "web", Health: "UNHEALTHY", Image: "registry.example.com/team/app:2"},
+					{Name: "envoy", Image: "envoy:v1"},
+				}},
+				{Containers: []client.Container{
+					{Name: "web", Health: "HEALTHY", Image: "registry.example.com/team/app:2"},
+				}},
+			},
+			want: [][]string{
+				{"api", "2", "1", "3", "IN_PROGRESS", "api:7", "envoy", "2 UNKNOWN", "envoy:v1"},
+				{"", "", "", "", "", "", "web", "2 HEALTHY, 1 UNHEALTHY", "app:1, app:2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ServiceRows(service, tt.tasks)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServiceRows() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTasksByService(t *testing.T) {
+	tasks := []client.Task{{ARN: "a", ServiceName: "api"}, {ARN: "b", ServiceName: "worker"}, {ARN: "c", ServiceName: "api"}}
+	got := TasksByService(tasks)
+	if len(got["api"]) != 2 || len(got["worker"]) != 1 {
+		t.Errorf("TasksByService() got = %+v", got)
+	}
+}
+
+func TestRecentEvents(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := func(ago time.Duration, message string) types.ServiceEvent {
+		return types.ServiceEvent{CreatedAt: aws.Time(now.Add(-ago)), Message: aws.String(message)}
+	}
+	services := []types.Service{
+		{
+			ServiceName: aws.String("api"),
+			Events: []types.ServiceEvent{
+				event(time.Minute, "api reached a steady state"),
+				event(3*time.Minute, "api started 1 task"),
+				event(5*time.Minute, "api registered 1 target"),
+			},
+		},
+		{
+			ServiceName: aws.String("worker"),
+			Events:      []types.ServiceEvent{event(2*time.Minute, "worker started 1 task")},
+		},
+	}
+
+	tests := []struct {
+		name string
+		max  int
+		want []string
+	}{
+		{
+			name: "newest first across services",
+			max:  2,
+			want: []string{"api reached a steady state", "worker started 1 task", "api started 1 task"},
+		},
+		{
+			name: "none",
+			max:  0,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range RecentEvents(services, tt.max) {
+				got = append(got, e.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RecentEvents() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventLines(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	events := []Event{
+		{Service: "api", CreatedAt: at, Message: "has reached a steady state."},
+		{Service: "worker", CreatedAt: at, Message: "has started 1 tasks."},
+	}
+	want := []string{
+		"[2024-05-01 12:00:00] api    has reached a steady state.",
+		"[2024-05-01 12:00:00] worker has started 1 tasks.",
+	}
+
+	got := EventLines(events, func(v interface{}) string { return fmt.Sprintf("[%v]", v) })
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EventLines() got = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,255 @@
+package taskdef
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"going/internal/utils"
+)
+
+// Change is a single difference between two task definitions. Container is
+// blank for task level fields. Added and Removed tell a missing value apart
+// from an empty one, Old or New are blank when the value was added or removed.
+type Change struct {
+	Container string
+	Field     string
+	Old       string
+	New       string
+	Added     bool
+	Removed   bool
+}
+
+func (c Change) String() string {
+	field := c.Field
+	if c.Container != "" {
+		field = c.Container + " " + c.Field
+	}
+	return fmt.Sprintf("%s %s: %s", c.Symbol(), field, c.Value())
+}
+
+// Symbol returns + for an added value, - for a removed value, and ~ for a changed value.
+func (c Change) Symbol() string {
+	switch {
+	case c.Added:
+		return "+"
+	case c.Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// Value returns the added or removed value, or both values for a change.
+func (c Change) Value() string {
+	switch {
+	case c.Added:
+		return c.New
+	case c.Removed:
+		return c.Old
+	default:
+		return fmt.Sprintf("%s -> %s", quoteEmpty(c.Old), quoteEmpty(c.New))
+	}
+}
+
+// quoteEmpty shows an empty value as "" so a change to or from it is visible.
+func quoteEmpty(v string) string {
+	if v == "" {
+		return `""`
+	}
+	return v
+}
+
+// Name returns the family and revision of the task definition.
+func Name(definition *types.TaskDefinition) string {
+	return fmt.Sprintf("%s:%d", aws.ToString(definition.Family), definition.Revision)
+}
+
+// Images returns the image of each container as name=image.
+func Images(definition *types.TaskDefinition) []string {
+	var images []string
+	for _, c := range definition.ContainerDefinitions {
+		images = append(images, fmt.Sprintf("%s=%s", aws.ToString(c.Name), shortImage(aws.ToString(c.Image))))
+	}
+	return images
+}
+
+// Diff returns the changes needed to go from one task definition to the other.
+func Diff(from *types.TaskDefinition, to *types.TaskDefinition) []Change {
+	var changes []Change
+	changes = appendChange(changes, "", "cpu", aws.ToString(from.Cpu), aws.ToString(to.Cpu))
+	changes = appendChange(changes, "", "memory", aws.ToString(from.Memory), aws.ToString(to.Memory))
+
+	fromContainers := containersByName(from)
+	toContainers := containersByName(to)
+	for _, name := range containerNames(fromContainers, toContainers) {
+		f, inFrom := fromContainers[name]
+		t, inTo := toContainers[name]
+		switch {
+		case !inFrom:
+			changes = append(changes, Change{Container: name, Field: "container", New: aws.ToString(t.Image), Added: true})
+		case !inTo:
+			changes = append(changes, Change{Container: name, Field: "container", Old: aws.ToString(f.Image), Removed: true})
+		default:
+			changes = append(changes, diffContainer(name, f, t)...)
+		}
+	}
+
+	return changes
+}
+
+func diffContainer(name string, from types.ContainerDefinition, to types.ContainerDefinition) []Change {
+	var changes []Change
+	changes = appendChange(changes, name, "image", aws.ToString(from.Image), aws.ToString(to.Image))
+	changes = appendChange(changes, name, "cpu", fmt.Sprint(from.Cpu), fmt.Sprint(to.Cpu))
+	changes = appendChange(changes, name, "memory", int32String(from.Memory), int32String(to.Memory))
+	changes = append(changes, diffMaps(name, "env", environment(from.Environment), environment(to.Environment))...)
+	changes = append(changes, diffMaps(name, "secret", secrets(from.Secrets), secrets(to.Secrets))...)
+	changes = append(changes, diffMaps(name, "port", ports(from.PortMappings), ports(to.PortMappings))...)
+
+	fromDriver, fromOptions := logConfiguration(from.LogConfiguration)
+	toDriver, toOptions := logConfiguration(to.LogConfiguration)
+	changes = appendChange(changes, name, "log driver", fromDriver, toDriver)
+	changes = append(changes, diffMaps(name, "log option", fromOptions, toOptions)...)
+
+	changes = appendChange(changes, name, "health check", healthCheck(from.HealthCheck), healthCheck(to.HealthCheck))
+	return changes
+}
+
+// diffMaps compares each key of the maps, the field is prefixed to the key.
+func diffMaps(container string, field string, from map[string]string, to map[string]string) []Change {
+	keys := map[string]struct{}{}
+	for k := range from {
+		keys[k] = struct{}{}
+	}
+	for k := range to {
+		keys[k] = struct{}{}
+	}
+
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, k := range sorted {
+		f, inFrom := from[k]
+		t, inTo := to[k]
+		if inFrom && inTo && f == t {
+			continue
+		}
+		changes = append(changes, Change{
+			Container: container, Field: field + " " + k, Old: f, New: t, Added: !inFrom, Removed: !inTo,
+		})
+	}
+	return changes
+}
+
+// appendChange compares a field that is blank when it isn't set.
+func appendChange(changes []Change, container string, field string, from string, to string) []Change {
+	if from == to {
+		return changes
+	}
+	return append(changes, Change{Container: container, Field: field, Old: from, New: to, Added: from == "", Removed: to == ""})
+}
+
+func environment(env []types.KeyValuePair) map[string]string {
+	m := map[string]string{}
+	for _, kv := range env {
+		m[aws.ToString(kv.Name)] = aws.ToString(kv.Value)
+	}
+	return m
+}
+
+// secrets maps the secret names to the ARN or parameter they reference.
+func secrets(secrets []types.Secret) map[string]string {
+	m := map[string]string{}
+	for _, s := range secrets {
+		m[aws.ToString(s.Name)] = aws.ToString(s.ValueFrom)
+	}
+	return m
+}
+
+// ports maps the container port and protocol to how it is mapped.
+func ports(mappings []types.PortMapping) map[string]string {
+	m := map[string]string{}
+	for _, p := range mappings {
+		protocol := string(p.Protocol)
+		if protocol == "" {
+			protocol = string(types.TransportProtocolTcp)
+		}
+
+		var details []string
+		if p.HostPort != nil {
+			details = append(details, fmt.Sprintf("host %d", *p.HostPort))
+		}
+		if p.Name != nil {
+			details = append(details, "name "+aws.ToString(p.Name))
+		}
+		if p.AppProtocol != "" {
+			details = append(details, "app protocol "+string(p.AppProtocol))
+		}
+		if len(details) == 0 {
+			details = append(details, "mapped")
+		}
+
+		m[fmt.Sprintf("%d/%s", aws.ToInt32(p.ContainerPort), protocol)] = strings.Join(details, ", ")
+	}
+	return m
+}
+
+func logConfiguration(config *types.LogConfiguration) (string, map[string]string) {
+	if config == nil {
+		return "", nil
+	}
+	return string(config.LogDriver), config.Options
+}
+
+// healthCheck returns the command and settings of the health check on one line.
+func healthCheck(check *types.HealthCheck) string {
+	if check == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s interval=%d timeout=%d retries=%d start=%d",
+		strings.Join(check.Command, " "), aws.ToInt32(check.Interval), aws.ToInt32(check.Timeout),
+		aws.ToInt32(check.Retries), aws.ToInt32(check.StartPeriod))
+}
+
+func containersByName(definition *types.TaskDefinition) map[string]types.ContainerDefinition {
+	containers := map[string]types.ContainerDefinition{}
+	for _, c := range definition.ContainerDefinitions {
+		containers[aws.ToString(c.Name)] = c
+	}
+	return containers
+}
+
+func containerNames(a map[string]types.ContainerDefinition, b map[string]types.ContainerDefinition) []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func int32String(i *int32) string {
+	if i == nil {
+		return ""
+	}
+	return fmt.Sprint(*i)
+}
+
+// shortImage removes the registry from the image name.
+func shortImage(image string) string {
+	name, _ := utils.Last(strings.Split(image, "/"))
+	return name
+}
@@ -0,0 +1,170 @@
+package taskdef
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestDiff(t *testing.T) {
+	web := types.ContainerDefinition{
+		Name:   aws.String("web"),
+		Image:  aws.String("registry/app:1"),
+		Cpu:    256,
+		Memory: aws.Int32(512),
+		Environment: []types.KeyValuePair{
+			{Name: aws.String("LOG_LEVEL"), Value: aws.String("info")},
+			{Name: aws.String("PORT"), Value: aws.String("8080")},
+		},
+	}
+	web2 := web
+	web2.Image = aws.String("registry/app:2")
+	web2.Memory = aws.Int32(1024)
+	web2.Environment = []types.KeyValuePair{
+		{Name: aws.String("LOG_LEVEL"), Value: aws.String("debug")},
+		{Name: aws.String("FEATURE"), Value: aws.String("on")},
+	}
+	sidecar := types.ContainerDefinition{Name: aws.String("sidecar"), Image: aws.String("envoy:1")}
+
+	api := types.ContainerDefinition{
+		Name:         aws.String("api"),
+		Secrets:      []types.Secret{{Name: aws.String("DB_PASSWORD"), ValueFrom: aws.String("arn:ssm:db/v1")}},
+		PortMappings: []types.PortMapping{{ContainerPort: aws.Int32(8080)}},
+		LogConfiguration: &types.LogConfiguration{
+			LogDriver: types.LogDriverAwslogs,
+			Options:   map[string]string{"awslogs-group": "/ecs/api"},
+		},
+	}
+	api2 := api
+	api2.Secrets = []types.Secret{{Name: aws.String("DB_PASSWORD"), ValueFrom: aws.String("arn:ssm:db/v2")}}
+	api2.PortMappings = []types.PortMapping{
+		{ContainerPort: aws.Int32(8080), HostPort: aws.Int32(80), Protocol: types.TransportProtocolTcp},
+	}
+	api2.LogConfiguration = &types.LogConfiguration{
+		LogDriver: types.LogDriverAwsfirelens,
+		Options:   map[string]string{"Name": "datadog"},
+	}
+	api2.HealthCheck = &types.HealthCheck{
+		Command:  []string{"CMD-SHELL", "curl -f localhost:8080"},
+		Interval: aws.Int32(30),
+		Timeout:  aws.Int32(5),
+		Retries:  aws.Int32(3),
+	}
+
+	tests := []struct {
+		name string
+		from *types.TaskDefinition
+		to   *types.TaskDefinition
+		want []Change
+	}{
+		{
+			name: "no changes",
+			from: &types.TaskDefinition{Cpu: aws.String("256"), ContainerDefinitions: []types.ContainerDefinition{web}},
+			to:   &types.TaskDefinition{Cpu: aws.String("256"), ContainerDefinitions: []types.ContainerDefinition{web}},
+			want: nil,
+		},
+		{
+			name: "task and container fields",
+			from: &types.TaskDefinition{Cpu: aws.String("256"), ContainerDefinitions: []types.ContainerDefinition{web}},
+			to:   &types.TaskDefinition{Cpu: aws.String("512"), ContainerDefinitions: []types.ContainerDefinition{web2}},
+			want: []Change{
+				{Field: "cpu", Old: "256", New: "512"},
+				{Container: "web", Field: "image", Old: "registry/app:1", New: "registry/app:2"},
+				{Container: "web", Field: "memory", Old: "512", New: "1024"},
+				{Container: "web", Field: "env FEATURE", New: "on", Added: true},
+				{Container: "web", Field: "env LOG_LEVEL", Old: "info", New: "debug"},
+				{Container: "web", Field: "env PORT", Old: "8080", Removed: true},
+			},
+		},
+		{
+			name: "secrets, ports, logs and health check",
+			from: &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{api}},
+			to:   &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{api2}},
+			want: []Change{
+				{Container: "api", Field: "secret DB_PASSWORD", Old: "arn:ssm:db/v1", New: "arn:ssm:db/v2"},
+				{Container: "api", Field: "port 8080/tcp", Old: "mapped", New: "host 80"},
+				{Container: "api", Field: "log driver", Old: "awslogs", New: "awsfirelens"},
+				{Container: "api", Field: "log option Name", New: "datadog", Added: true},
+				{Container: "api", Field: "log option awslogs-group", Old: "/ecs/api", Removed: true},
+				{
+					Container: "api",
+					Field:     "health check",
+					New:       "CMD-SHELL curl -f localhost:8080 interval=30 timeout=5 retries=3 start=0",
+					Added:     true,
+				},
+			},
+		},
+		{
+			name: "containers added and removed",
+			from: &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{web}},
+			to:   &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{sidecar}},
+			want: []Change{
+				{Container: "sidecar", Field: "container", New: "envoy:1", Added: true},
+				{Container: "web", Field: "container", Old: "registry/app:1", Removed: true},
+			},
+		},
+		{
+			name: "empty values",
+			from: &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{{
+				Name:        aws.String("web"),
+				Environment: []types.KeyValuePair{{Name: aws.String("DEBUG"), Value: aws.String("")}},
+			}}},
+			to: &types.TaskDefinition{ContainerDefinitions: []types.ContainerDefinition{{
+				Name: aws.String("web"),
+				Environment: []types.KeyValuePair{
+					{Name: aws.String("DEBUG"), Value: aws.String("1")},
+					{Name: aws.String("PREFIX"), Value: aws.String("")},
+				},
+			}}},
+			want: []Change{
+				{Container: "web", Field: "env DEBUG", New: "1"},
+				{Container: "web", Field: "env PREFIX", Added: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		change Change
+		want   string
+	}{
+		{
+			name:   "added",
+			change: Change{Container: "web", Field: "env FEATURE", New: "on", Added: true},
+			want:   "+ web env FEATURE: on",
+		},
+		{
+			name:   "removed",
+			change: Change{Container: "web", Field: "container", Old: "app:1", Removed: true},
+			want:   "- web container: app:1",
+		},
+		{
+			name:   "changed task field",
+			change: Change{Field: "cpu", Old: "256", New: "512"},
+			want:   "~ cpu: 256 -> 512",
+		},
+		{
+			name:   "changed from empty",
+			change: Change{Container: "web", Field: "env DEBUG", New: "1"},
+			want:   `~ web env DEBUG: "" -> 1`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.String(); got != tt.want {
+				t.Errorf("String() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
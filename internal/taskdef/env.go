@@ -0,0 +1,222 @@
+package taskdef
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// The sources a variable can come from.
+const (
+	SourceEnvironment     = "environment"
+	SourceEnvironmentFile = "file"
+	SourceSSM             = "ssm"
+	SourceSecretsManager  = "secretsmanager"
+)
+
+// Variable is an environment variable of a container.
+type Variable struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+	// From is the S3 object, parameter, or secret the value is read from.
+	From   string `json:"from" yaml:"from"`
+	Secret bool   `json:"secret" yaml:"secret"`
+}
+
+// Resolver reads the environment files and secrets of a container.
+type Resolver interface {
+	GetObject(objectARN string) ([]byte, error)
+	GetParameter(name string) (string, error)
+	GetSecretValue(secretID string, versionStage string, versionID string) (string, error)
+}
+
+// Environment returns the variables the container is started with, sorted by
+// name. Like ECS, variables set in the container's environment take
+// precedence over environment files, and later environment files take
+// precedence over earlier ones. Secrets take precedence over both. Secrets
+// are only read when reveal is true, otherwise their values are empty and
+// only From refers to them.
+func Environment(container types.ContainerDefinition, r Resolver, reveal bool) ([]Variable, error) {
+	variables := map[string]Variable{}
+
+	for _, file := range container.EnvironmentFiles {
+		objectARN := aws.ToString(file.Value)
+		b, err := r.GetObject(objectARN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment file %s, %w", objectARN, err)
+		}
+
+		env, err := parseEnvFile(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse environment file %s, %w", objectARN, err)
+		}
+		for name, value := range env {
+			variables[name] = Variable{Name: name, Value: value, Source: SourceEnvironmentFile, From: objectARN}
+		}
+	}
+
+	for _, kv := range container.Environment {
+		name := aws.ToString(kv.Name)
+		variables[name] = Variable{Name: name, Value: aws.ToString(kv.Value), Source: SourceEnvironment}
+	}
+
+	for _, secret := range container.Secrets {
+		if !reveal {
+			v := secretVariable(secret)
+			variables[v.Name] = v
+			continue
+		}
+		v, err := resolveSecret(secret, r)
+		if err != nil {
+			return nil, err
+		}
+		variables[v.Name] = v
+	}
+
+	var result []Variable
+	for _, v := range variables {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// parseEnvFile parses an ECS environment file. Each line is VARIABLE=VALUE,
+// blank lines and lines starting with # are ignored. Values aren't unquoted.
+func parseEnvFile(b []byte) (map[string]string, error) {
+	env := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(text, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d isn't VARIABLE=VALUE", line)
+		}
+		env[name] = value
+	}
+
+	return env, scanner.Err()
+}
+
+// FormatDotenv returns the variables as the lines of a .env file. Unless the
+// secrets are revealed they are written as comments naming where they are
+// read from.
+func FormatDotenv(variables []Variable, revealed bool) string {
+	var b strings.Builder
+	for _, v := range variables {
+		if v.Secret && !revealed {
+			fmt.Fprintf(&b, "# %s is read from %s\n", v.Name, v.From)
+			continue
+		}
+		value := v.Value
+		if strings.ContainsAny(value, " \t\n\"'#$\\") {
+			value = dotenvQuote(value)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Name, value)
+	}
+	return b.String()
+}
+
+// dotenvQuoter escapes the characters dotenv parsers expand in double quotes.
+var dotenvQuoter = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`)
+
+// dotenvQuote double quotes the value the way dotenv files do, unlike Go
+// quoting other characters such as non-ASCII letters are kept as they are.
+func dotenvQuote(value string) string {
+	return `"` + dotenvQuoter.Replace(value) + `"`
+}
+
+// secretVariable returns the variable of the secret without its value.
+func secretVariable(secret types.Secret) Variable {
+	v := Variable{Name: aws.ToString(secret.Name), Source: SourceSSM, From: aws.ToString(secret.ValueFrom), Secret: true}
+	if strings.Contains(v.From, ":secretsmanager:") {
+		v.Source = SourceSecretsManager
+	}
+	return v
+}
+
+func resolveSecret(secret types.Secret, r Resolver) (Variable, error) {
+	v := secretVariable(secret)
+
+	if v.Source == SourceSSM {
+		value, err := r.GetParameter(v.From)
+		if err != nil {
+			return Variable{}, fmt.Errorf("failed to get parameter %s for %s, %w", v.From, v.Name, err)
+		}
+		v.Value = value
+		return v, nil
+	}
+
+	ref := parseSecretRef(v.From)
+	value, err := r.GetSecretValue(ref.ARN, ref.VersionStage, ref.VersionID)
+	if err != nil {
+		return Variable{}, fmt.Errorf("failed to get secret %s for %s, %w", ref.ARN, v.Name, err)
+	}
+
+	if ref.JSONKey != "" {
+		value, err = jsonKey(value, ref.JSONKey)
+		if err != nil {
+			return Variable{}, fmt.Errorf("failed to get key %s of secret %s for %s, %w", ref.JSONKey, ref.ARN, v.Name, err)
+		}
+	}
+
+	v.Value = value
+	return v, nil
+}
+
+// secretRef is a Secrets Manager reference in the form
+// arn:aws:secretsmanager:region:account:secret:name:json-key:version-stage:version-id
+// where the last three parts are optional.
+type secretRef struct {
+	ARN          string
+	JSONKey      string
+	VersionStage string
+	VersionID    string
+}
+
+// parseSecretRef splits a Secrets Manager reference into its parts.
+func parseSecretRef(valueFrom string) secretRef {
+	parts := strings.Split(valueFrom, ":")
+	if len(parts) <= 7 {
+		return secretRef{ARN: valueFrom}
+	}
+
+	ref := secretRef{ARN: strings.Join(parts[:7], ":")}
+	optional := append(parts[7:], "", "", "")
+	ref.JSONKey, ref.VersionStage, ref.VersionID = optional[0], optional[1], optional[2]
+	return ref
+}
+
+// jsonKey returns the value of the key in the JSON object secret.
+func jsonKey(secret string, key string) (string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", err
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("key not found")
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	b, err := json.Marshal(value)
+	return string(b), err
+}
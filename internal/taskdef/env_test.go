@@ -0,0 +1,180 @@
+package taskdef
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+type fakeResolver struct {
+	objects    map[string]string
+	parameters map[string]string
+	secrets    map[string]string
+}
+
+func (r fakeResolver) GetObject(objectARN string) ([]byte, error) {
+	if o, ok := r.objects[objectARN]; ok {
+		return []byte(o), nil
+	}
+	return nil, errors.New("no such key")
+}
+
+func (r fakeResolver) GetParameter(name string) (string, error) {
+	if p, ok := r.parameters[name]; ok {
+		return p, nil
+	}
+	return "", errors.New("parameter not found")
+}
+
+func (r fakeResolver) GetSecretValue(secretID string, versionStage string, versionID string) (string, error) {
+	if s, ok := r.secrets[secretID+versionStage+versionID]; ok {
+		return s, nil
+	}
+	return "", errors.New("secret not found")
+}
+
+func TestEnvironment(t *testing.T) {
+	secretARN := "arn:aws:secretsmanager:eu-west-1:123456789012:secret:db-AbCdEf"
+	r := fakeResolver{
+		objects: map[string]string{
+			"arn:aws:s3:::config/base.env":     "# base\nLOG_LEVEL=warn\nREGION=eu-west-1\n\nTIMEOUT=30\n",
+			"arn:aws:s3:::config/override.env": "TIMEOUT=60\n",
+			"arn:aws:s3:::config/invalid.env":  "NOT A VARIABLE\n",
+		},
+		parameters: map[string]string{"/api/token": "s3cr3t"},
+		secrets: map[string]string{
+			secretARN:                 `{"password":"hunter2","port":5432}`,
+			secretARN + "AWSPREVIOUS": `{"password":"old"}`,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		container types.ContainerDefinition
+		reveal    bool
+		want      []Variable
+		wantErr   bool
+	}{
+		{
+			name: "merges files, environment, and secrets",
+			container: types.ContainerDefinition{
+				EnvironmentFiles: []types.EnvironmentFile{
+					{Value: aws.String("arn:aws:s3:::config/base.env")},
+					{Value: aws.String("arn:aws:s3:::config/override.env")},
+				},
+				Environment: []types.KeyValuePair{{Name: aws.String("LOG_LEVEL"), Value: aws.String("info")}},
+				Secrets: []types.Secret{
+					{Name: aws.String("API_TOKEN"), ValueFrom: aws.String("/api/token")},
+					{Name: aws.String("DB_PASSWORD"), ValueFrom: aws.String(secretARN + ":password::")},
+					{Name: aws.String("DB_PORT"), ValueFrom: aws.String(secretARN + ":port::")},
+					{Name: aws.String("OLD_PASSWORD"), ValueFrom: aws.String(secretARN + ":password:AWSPREVIOUS:")},
+				},
+			},
+			reveal: true,
+			want: []Variable{
+				{Name: "API_TOKEN", Value: "s3cr3t", Source: SourceSSM, From: "/api/token", Secret: true},
+				{Name: "DB_PASSWORD", Value: "hunter2", Source: SourceSecretsManager, From: secretARN + ":password::", Secret: true},
+				{Name: "DB_PORT", Value: "5432", Source: SourceSecretsManager, From: secretARN + ":port::", Secret: true},
+				{Name: "LOG_LEVEL", Value: "info", Source: SourceEnvironment},
+				{
+					Name:   "OLD_PASSWORD",
+					Value:  "old",
+					Source: SourceSecretsManager,
+					From:   secretARN + ":password:AWSPREVIOUS:",
+					Secret: true,
+				},
+				{Name: "REGION", Value: "eu-west-1", Source: SourceEnvironmentFile, From: "arn:aws:s3:::config/base.env"},
+				{Name: "TIMEOUT", Value: "60", Source: SourceEnvironmentFile, From: "arn:aws:s3:::config/override.env"},
+			},
+		},
+		{
+			name: "secrets aren't read unless revealed",
+			container: types.ContainerDefinition{
+				Environment: []types.KeyValuePair{{Name: aws.String("LOG_LEVEL"), Value: aws.String("info")}},
+				Secrets: []types.Secret{
+					{Name: aws.String("MISSING"), ValueFrom: aws.String("/missing")},
+					{Name: aws.String("USER"), ValueFrom: aws.String(secretARN + ":username::")},
+				},
+			},
+			want: []Variable{
+				{Name: "LOG_LEVEL", Value: "info", Source: SourceEnvironment},
+				{Name: "MISSING", Source: SourceSSM, From: "/missing", Secret: true},
+				{Name: "USER", Source: SourceSecretsManager, From: secretARN + ":username::", Secret: true},
+			},
+		},
+		{
+			name: "invalid environment file",
+			container: types.ContainerDefinition{
+				EnvironmentFiles: []types.EnvironmentFile{{Value: aws.String("arn:aws:s3:::config/invalid.env")}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing parameter",
+			container: types.ContainerDefinition{
+				Secrets: []types.Secret{{Name: aws.String("MISSING"), ValueFrom: aws.String("/missing")}},
+			},
+			reveal:  true,
+			wantErr: true,
+		},
+		{
+			name: "missing secret key",
+			container: types.ContainerDefinition{
+				Secrets: []types.Secret{{Name: aws.String("USER"), ValueFrom: aws.String(secretARN + ":username::")}},
+			},
+			reveal:  true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Environment(tt.container, r, tt.reveal)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Environment() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Environment() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDotenv(t *testing.T) {
+	variables := []Variable{
+		{Name: "EMPTY"},
+		{Name: "PLAIN", Value: "value"},
+		{Name: "SPACES", Value: "hello world"},
+		{Name: "QUOTES", Value: `say "hi"`},
+		{Name: "SPECIAL", Value: "café $HOME\\n\nnext"},
+		{Name: "TOKEN", Value: "s3cr3t", From: "/api/token", Secret: true},
+	}
+
+	tests := []struct {
+		name     string
+		revealed bool
+		want     string
+	}{
+		{
+			name:     "revealed",
+			revealed: true,
+			want: "EMPTY=\nPLAIN=value\nSPACES=\"hello world\"\nQUOTES=\"say \\\"hi\\\"\"\n" +
+				"SPECIAL=\"café \\$HOME\\\\n\\nnext\"\nTOKEN=s3cr3t\n",
+		},
+		{
+			name: "secrets as comments",
+			want: "EMPTY=\nPLAIN=value\nSPACES=\"hello world\"\nQUOTES=\"say \\\"hi\\\"\"\n" +
+				"SPECIAL=\"café \\$HOME\\\\n\\nnext\"\n# TOKEN is read from /api/token\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDotenv(variables, tt.revealed); got != tt.want {
+				t.Errorf("FormatDotenv() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package taskdef
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// firelensCloudWatchOutputs are the Fluent Bit outputs that send to CloudWatch
+// Logs, the newer cloudwatch_logs plugin and the older cloudwatch one.
+var firelensCloudWatchOutputs = []string{"cloudwatch_logs", "cloudwatch"}
+
+// LogConfig is where a container's logs are sent in CloudWatch Logs, by the
+// awslogs driver or a FireLens log router with a CloudWatch output.
+type LogConfig struct {
+	GroupName    string
+	StreamPrefix string
+	// FireLens is true when the logs are routed through FireLens.
+	FireLens bool
+	// FixedStream is the one stream every task writes to when FireLens sets
+	// log_stream_name instead of a prefix.
+	FixedStream string
+	// Templated is true when FireLens names streams with a template, the
+	// streams then only share the StreamPrefix before the template.
+	Templated bool
+}
+
+// CloudWatchLogs returns the log group and stream prefix of a container using
+// the awslogs driver or FireLens with a CloudWatch output. The errors of
+// other drivers explain where the logs go instead.
+func CloudWatchLogs(container types.ContainerDefinition) (LogConfig, error) {
+	name := aws.ToString(container.Name)
+	lc := container.LogConfiguration
+	if lc == nil {
+		return LogConfig{}, fmt.Errorf("no log configuration found for container '%s', "+
+			"its logs aren't sent to CloudWatch", name)
+	}
+
+	switch lc.LogDriver {
+	case types.LogDriverAwslogs:
+		return awsLogs(name, lc.Options)
+	case types.LogDriverAwsfirelens:
+		return fireLens(name, lc.Options)
+	default:
+		return LogConfig{}, fmt.Errorf("container '%s' uses the %s log driver which going can't read, "+
+			"if the logs also reach CloudWatch use --group and --stream-prefix", name, lc.LogDriver)
+	}
+}
+
+// StreamName returns the log stream of the container in the task. The
+// awslogs driver names the stream prefix/container/task-id, FireLens names it
+// after the log router's tag, container-firelens-task-id, after the prefix.
+// It's only the task's stream if TaskStreams is true.
+func (l LogConfig) StreamName(container string, taskID string) string {
+	if l.FixedStream != "" {
+		return l.FixedStream
+	}
+	if l.FireLens {
+		return fmt.Sprintf("%s%s-firelens-%s", l.StreamPrefix, container, taskID)
+	}
+	return fmt.Sprintf("%s/%s/%s", l.StreamPrefix, container, taskID)
+}
+
+// ContainerPrefix returns the prefix of the container's streams from every
+// task, empty if the streams can't be told apart from other containers'.
+func (l LogConfig) ContainerPrefix(container string) string {
+	switch {
+	case l.FixedStream != "":
+		return l.FixedStream
+	case l.Templated:
+		return l.StreamPrefix
+	case l.FireLens:
+		return fmt.Sprintf("%s%s-firelens-", l.StreamPrefix, container)
+	case l.StreamPrefix == "":
+		return ""
+	default:
+		return fmt.Sprintf("%s/%s/", l.StreamPrefix, container)
+	}
+}
+
+// TaskStreamPrefix returns the prefix matching the container's stream in the
+// task, which also matches it before it's created. It's the task's stream
+// name if TaskStreams is true, otherwise the container's prefix.
+func (l LogConfig) TaskStreamPrefix(container string, taskID string) string {
+	if l.TaskStreams() {
+		return l.StreamName(container, taskID)
+	}
+	return l.ContainerPrefix(container)
+}
+
+// TaskStreams checks if each task writes to a stream named after its ID.
+// Without a prefix the awslogs driver names streams after container IDs.
+func (l LogConfig) TaskStreams() bool {
+	return l.FixedStream == "" && !l.Templated && (l.FireLens || l.StreamPrefix != "")
+}
+
+func awsLogs(container string, options map[string]string) (LogConfig, error) {
+	group, ok := options["awslogs-group"]
+	if !ok {
+		return LogConfig{}, fmt.Errorf("no log group found for container '%s'", container)
+	}
+	return LogConfig{GroupName: group, StreamPrefix: options["awslogs-stream-prefix"]}, nil
+}
+
+func fireLens(container string, options map[string]string) (LogConfig, error) {
+	output := options["Name"]
+	if output == "" {
+		return LogConfig{}, fmt.Errorf("container '%s' routes its logs through FireLens without a Name option, "+
+			"the outputs of a custom Fluent Bit config can't be read, use --group and --stream-prefix", container)
+	}
+
+	cloudWatch := false
+	for _, o := range firelensCloudWatchOutputs {
+		if strings.EqualFold(output, o) {
+			cloudWatch = true
+		}
+	}
+	if !cloudWatch {
+		return LogConfig{}, fmt.Errorf("container '%s' routes its logs through FireLens to the %s output "+
+			"which going can't read, if the logs also reach CloudWatch use --group and --stream-prefix", container, output)
+	}
+
+	group := options["log_group_name"]
+	if group == "" {
+		return LogConfig{}, fmt.Errorf("no log_group_name found in the FireLens options of container '%s'", container)
+	}
+	if isTemplated(group) {
+		return LogConfig{}, fmt.Errorf("the FireLens log_group_name '%s' of container '%s' is templated, "+
+			"use --group with the resolved name", group, container)
+	}
+
+	config := LogConfig{GroupName: group, StreamPrefix: options["log_stream_prefix"], FireLens: true}
+	if stream := options["log_stream_name"]; stream != "" {
+		// Templated names differ per record, everything before the template is shared.
+		if isTemplated(stream) {
+			config.StreamPrefix = stream[:strings.Index(stream, "$")]
+			config.Templated = true
+		} else {
+			config.FixedStream = stream
+		}
+	}
+	return config, nil
+}
+
+// isTemplated checks for Fluent Bit record accessor templates like $(ecs_task_id).
+func isTemplated(s string) bool {
+	return strings.Contains(s, "$(") || strings.Contains(s, "${")
+}
@@ -0,0 +1,127 @@
+package taskdef
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+func TestCloudWatchLogs(t *testing.T) {
+	container := func(driver types.LogDriver, options map[string]string) types.ContainerDefinition {
+		return types.ContainerDefinition{
+			Name:             aws.String("web"),
+			LogConfiguration: &types.LogConfiguration{LogDriver: driver, Options: options},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		container  types.ContainerDefinition
+		want       LogConfig
+		wantStream string
+		wantPrefix string
+		// wantTask is the prefix of task aaa's stream.
+		wantTask string
+		wantErr  bool
+	}{
+		{
+			name:       "awslogs",
+			container:  container(types.LogDriverAwslogs, map[string]string{"awslogs-group": "/ecs/api", "awslogs-stream-prefix": "ecs"}),
+			want:       LogConfig{GroupName: "/ecs/api", StreamPrefix: "ecs"},
+			wantStream: "ecs/web/aaa",
+			wantPrefix: "ecs/web/",
+			wantTask:   "ecs/web/aaa",
+		},
+		{
+			name:      "awslogs without prefix",
+			container: container(types.LogDriverAwslogs, map[string]string{"awslogs-group": "/ecs/api"}),
+			want:      LogConfig{GroupName: "/ecs/api"},
+		},
+		{
+			name:      "awslogs without group",
+			container: container(types.LogDriverAwslogs, map[string]string{"awslogs-region": "eu-west-1"}),
+			wantErr:   true,
+		},
+		{
+			name: "firelens cloudwatch_logs",
+			container: container(types.LogDriverAwsfirelens, map[string]string{
+				"Name": "cloudwatch_logs", "log_group_name": "/ecs/api", "log_stream_prefix": "api-",
+			}),
+			want:       LogConfig{GroupName: "/ecs/api", StreamPrefix: "api-", FireLens: true},
+			wantStream: "api-web-firelens-aaa",
+			wantPrefix: "api-web-firelens-",
+			wantTask:   "api-web-firelens-aaa",
+		},
+		{
+			name: "firelens fixed stream",
+			container: container(types.LogDriverAwsfirelens, map[string]string{
+				"Name": "cloudwatch", "log_group_name": "/ecs/api", "log_stream_name": "api",
+			}),
+			want:       LogConfig{GroupName: "/ecs/api", FireLens: true, FixedStream: "api"},
+			wantStream: "api",
+			wantPrefix: "api",
+			wantTask:   "api",
+		},
+		{
+			name: "firelens templated stream",
+			container: container(types.LogDriverAwsfirelens, map[string]string{
+				"Name": "cloudwatch_logs", "log_group_name": "/ecs/api", "log_stream_name": "api/$(ecs_task_id)",
+			}),
+			want:       LogConfig{GroupName: "/ecs/api", StreamPrefix: "api/", FireLens: true, Templated: true},
+			wantPrefix: "api/",
+			wantTask:   "api/",
+		},
+		{
+			name: "firelens templated group",
+			container: container(types.LogDriverAwsfirelens, map[string]string{
+				"Name": "cloudwatch_logs", "log_group_name": "/ecs/$(ecs_cluster)",
+			}),
+			wantErr: true,
+		},
+		{
+			name:      "firelens to datadog",
+			container: container(types.LogDriverAwsfirelens, map[string]string{"Name": "datadog"}),
+			wantErr:   true,
+		},
+		{
+			name:      "firelens config file",
+			container: container(types.LogDriverAwsfirelens, nil),
+			wantErr:   true,
+		},
+		{
+			name:      "splunk",
+			container: container(types.LogDriverSplunk, map[string]string{"splunk-url": "https://splunk"}),
+			wantErr:   true,
+		},
+		{
+			name:      "no log configuration",
+			container: types.ContainerDefinition{Name: aws.String("web")},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CloudWatchLogs(tt.container)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CloudWatchLogs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CloudWatchLogs() = %+v, want %+v", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			// Streams that aren't named after tasks can't be named.
+			if stream := got.StreamName("web", "aaa"); tt.wantStream != "" && stream != tt.wantStream {
+				t.Errorf("StreamName() = %v, want %v", stream, tt.wantStream)
+			}
+			if prefix := got.ContainerPrefix("web"); prefix != tt.wantPrefix {
+				t.Errorf("ContainerPrefix() = %v, want %v", prefix, tt.wantPrefix)
+			}
+			if prefix := got.TaskStreamPrefix("web", "aaa"); prefix != tt.wantTask {
+				t.Errorf("TaskStreamPrefix() = %v, want %v", prefix, tt.wantTask)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"reflect"
 	"strings"
 
 	"github.com/chzyer/readline"
@@ -26,15 +29,21 @@ func (n *noBellStdout) Close() error {
 
 const selectItemSize = 10
 
+// Prompt asks the user to pick an item or confirm. The errors of a cancelled
+// prompt are shown as cancelled by CheckErr.
 type Prompt interface {
-	Select(label string, items []string) (value string)
-	CustomSelect(label string, items interface{}, tmpl *promptui.SelectTemplates, searcher list.Searcher) (index int)
-	YesNoPrompt(label string) bool
+	Select(label string, items []string) (value string, err error)
+	CustomSelect(label string, items interface{}, tmpl *promptui.SelectTemplates, searcher list.Searcher) (index int, err error)
+	YesNoPrompt(label string) (bool, error)
 }
 
-type Prompter struct{}
+// Prompter prompts the user in the terminal. If AssumeYes is set then
+// confirmations are skipped.
+type Prompter struct {
+	AssumeYes bool
+}
 
-func (p Prompter) Select(label string, items []string) string {
+func (p Prompter) Select(label string, items []string) (string, error) {
 	prompt := promptui.Select{
 		Label:             label,
 		Items:             items,
@@ -45,12 +54,10 @@ func (p Prompter) Select(label string, items []string) string {
 	}
 
 	_, result, err := prompt.Run()
-	CheckErr(err)
-
-	return result
+	return result, err
 }
 
-func (p Prompter) CustomSelect(label string, items interface{}, tmpl *promptui.SelectTemplates, searcher list.Searcher) int {
+func (p Prompter) CustomSelect(label string, items interface{}, tmpl *promptui.SelectTemplates, searcher list.Searcher) (int, error) {
 	prompt := promptui.Select{
 		Label:             label,
 		Items:             items,
@@ -61,12 +68,14 @@ func (p Prompter) CustomSelect(label string, items interface{}, tmpl *promptui.S
 		Stdout:            &noBellStdout{},
 	}
 	i, _, err := prompt.Run()
-	CheckErr(err)
-
-	return i
+	return i, err
 }
 
-func (p Prompter) YesNoPrompt(label string) bool {
+func (p Prompter) YesNoPrompt(label string) (bool, error) {
+	if p.AssumeYes {
+		return true, nil
+	}
+
 	prompt := promptui.Prompt{
 		Label:     label,
 		IsConfirm: true,
@@ -83,9 +92,9 @@ func (p Prompter) YesNoPrompt(label string) bool {
 	_, err := prompt.Run()
 	aborted := errors.Is(err, promptui.ErrAbort)
 	if !aborted && err == nil {
-		return true
+		return true, nil
 	} else {
-		return false
+		return false, nil
 	}
 }
 
@@ -98,3 +107,58 @@ func fuzzyStringSearch(itemsToSelect []string) func(input string, index int) boo
 		return false
 	}
 }
+
+// ErrNonInteractive is returned when a prompt is needed but going is running non-interactively.
+var ErrNonInteractive = errors.New("running non-interactively")
+
+// NonInteractivePrompter is used when there is no terminal to prompt in. Any
+// prompt returns ErrNonInteractive listing the candidates, and confirmations
+// fail unless AssumeYes is set.
+type NonInteractivePrompter struct {
+	AssumeYes bool
+}
+
+func (p NonInteractivePrompter) Select(label string, items []string) (string, error) {
+	return "", fmt.Errorf("%w, cannot prompt \"%s\", the candidates are: %s",
+		ErrNonInteractive, label, strings.Join(items, ", "))
+}
+
+func (p NonInteractivePrompter) CustomSelect(label string, items interface{}, _ *promptui.SelectTemplates, _ list.Searcher) (int, error) {
+	var candidates []string
+	v := reflect.ValueOf(items)
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			candidates = append(candidates, fmt.Sprint(v.Index(i).Interface()))
+		}
+	}
+
+	return -1, fmt.Errorf("%w, cannot prompt \"%s\", the candidates are: %s",
+		ErrNonInteractive, label, strings.Join(candidates, ", "))
+}
+
+func (p NonInteractivePrompter) YesNoPrompt(label string) (bool, error) {
+	if p.AssumeYes {
+		return true, nil
+	}
+
+	return false, fmt.Errorf("%w, \"%s\" needs confirmation, use --yes to confirm", ErrNonInteractive, label)
+}
+
+// StdinIsTerminal reports whether stdin is a terminal that can be prompted in.
+func StdinIsTerminal() bool {
+	return readline.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// StdoutIsTerminal reports whether stdout is a terminal that can show colors.
+func StdoutIsTerminal() bool {
+	return readline.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Styled returns the styler, such as one from promptui.Styler, when stdout is a
+// terminal. Otherwise text is left as is so piped output has no escape codes.
+func Styled(style func(interface{}) string) func(interface{}) string {
+	if StdoutIsTerminal() {
+		return style
+	}
+	return func(v interface{}) string { return fmt.Sprint(v) }
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNonInteractivePrompter(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       NonInteractivePrompter
+		run     func(p NonInteractivePrompter) error
+		wantErr bool
+	}{
+		{
+			name: "select",
+			run: func(p NonInteractivePrompter) error {
+				_, err := p.Select("Select a cluster", []string{"main", "staging"})
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom select",
+			run: func(p NonInteractivePrompter) error {
+				_, err := p.CustomSelect("Select a container", []string{"web"}, nil, nil)
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "confirm",
+			run: func(p NonInteractivePrompter) error {
+				_, err := p.YesNoPrompt("Restart all tasks")
+				return err
+			},
+			wantErr: true,
+		},
+		{
+			name: "confirm with --yes",
+			p:    NonInteractivePrompter{AssumeYes: true},
+			run: func(p NonInteractivePrompter) error {
+				yes, err := p.YesNoPrompt("Restart all tasks")
+				if !yes {
+					return errors.New("not confirmed")
+				}
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrNonInteractive) {
+				t.Errorf("error = %v, want ErrNonInteractive", err)
+			}
+		})
+	}
+}